@@ -0,0 +1,147 @@
+// Command admin manages the API keys clients authenticate with and signs JWTs.
+//
+//	admin issue -name <client> [-role operator|admin]
+//	admin list
+//	admin revoke -id <key id>
+//	admin token -subject <client> [-role operator|admin] [-ttl 1h] [-hs256-key-file <file> | -rs256-private-key-file <file>]
+package main
+
+import (
+	"context"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/config"
+	"devTodTestTask/internal/repo"
+	"devTodTestTask/internal/services"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `usage:
+  admin issue -name <client> [-role operator|admin]
+  admin list
+  admin revoke -id <key id>
+  admin token -subject <client> [-role operator|admin] [-ttl 1h] [-hs256-key-file <file> | -rs256-private-key-file <file>]`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	// Signing a token needs only the key, not the database
+	if os.Args[1] == "token" {
+		if err := token(cfg.Tokens, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "token failed. Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.RequestTimeout)
+	defer cancel()
+
+	db := config.ConnectDB()
+	defer db.Close()
+	service := &services.AuthService{Keys: &repo.APIKeyRepository{DB: db}}
+
+	var err error
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "issue":
+		err = issue(ctx, service, args)
+	case "list":
+		err = list(ctx, service)
+	case "revoke":
+		err = revoke(ctx, service, args)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed. Error: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func issue(ctx context.Context, service *services.AuthService, args []string) error {
+	flags := flag.NewFlagSet("issue", flag.ExitOnError)
+	name := flags.String("name", "", "client the key is issued to")
+	role := flags.String("role", string(auth.RoleOperator), "role of the key: operator or admin")
+	flags.Parse(args)
+
+	key, stored, err := service.IssueAPIKey(ctx, *name, auth.Role(*role))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Issued API key %d for %s (%s). Store it now, it cannot be shown again:\n%s\n", stored.ID, stored.Name, stored.Role, key)
+	return nil
+}
+
+func list(ctx context.Context, service *services.AuthService) error {
+	keys, err := service.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tROLE\tCREATED\tLAST USED\tREVOKED")
+	for _, key := range keys {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Role,
+			formatTime(key.CreatedAt), formatTime(key.LastUsedAt), formatTime(key.RevokedAt))
+	}
+	return w.Flush()
+}
+
+func revoke(ctx context.Context, service *services.AuthService, args []string) error {
+	flags := flag.NewFlagSet("revoke", flag.ExitOnError)
+	id := flags.Uint("id", 0, "ID of the key to revoke, as shown by list")
+	flags.Parse(args)
+
+	if *id == 0 {
+		return fmt.Errorf("-id is required")
+	}
+	if err := service.RevokeAPIKey(ctx, *id); err != nil {
+		return err
+	}
+	fmt.Printf("Revoked API key %d\n", *id)
+	return nil
+}
+
+// token prints a JWT for the subject, signed with the server's HS256 key unless
+// another key is given. RS256 tokens need the private half of JWT_RS256_PUBLIC_KEY_FILE.
+func token(cfg auth.TokenConfig, args []string) error {
+	flags := flag.NewFlagSet("token", flag.ExitOnError)
+	subject := flags.String("subject", "", "client the token is issued to")
+	role := flags.String("role", string(auth.RoleOperator), "role of the token: operator or admin")
+	ttl := flags.Duration("ttl", time.Hour, "lifetime of the token")
+	hs256 := flags.String("hs256-key-file", "", "HS256 secret to sign with (default JWT_HS256_KEY_FILE)")
+	rs256 := flags.String("rs256-private-key-file", "", "PEM encoded RSA private key to sign with")
+	issuer := flags.String("issuer", cfg.Issuer, "iss claim")
+	audience := flags.String("audience", cfg.Audience, "aud claim")
+	flags.Parse(args)
+
+	if *hs256 == "" && *rs256 == "" {
+		*hs256 = cfg.HS256KeyFile
+	}
+	tokens, err := auth.NewTokenIssuer(*hs256, *rs256, *issuer, *audience)
+	if err != nil {
+		return err
+	}
+	signed, err := tokens.Issue(auth.Principal{Subject: *subject, Role: auth.Role(*role)}, *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(signed)
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
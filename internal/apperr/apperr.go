@@ -0,0 +1,108 @@
+// Package apperr defines the typed errors returned by the repositories and
+// services. The error middleware maps each Kind to an HTTP status code.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindUnauthorized
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindForbidden
+	KindPreconditionFailed
+	KindPreconditionRequired
+)
+
+type Error struct {
+	Kind Kind
+	// Code is a stable, machine-readable identifier such as "mission_not_found".
+	Code    string
+	Message string
+	// Details carries extra structured data for the client, e.g. allowed transitions.
+	Details map[string]interface{}
+	// Fields lists the individual fields that failed validation.
+	Fields []FieldError
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of the error carrying the given details.
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// WithFields returns a copy of the error carrying the given field errors.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	copied := *e
+	copied.Fields = fields
+	return &copied
+}
+
+func newError(kind Kind, code, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func BadRequest(code, format string, args ...interface{}) *Error {
+	return newError(KindBadRequest, code, format, args...)
+}
+
+func Unauthorized(code, format string, args ...interface{}) *Error {
+	return newError(KindUnauthorized, code, format, args...)
+}
+
+func NotFound(code, format string, args ...interface{}) *Error {
+	return newError(KindNotFound, code, format, args...)
+}
+
+func Conflict(code, format string, args ...interface{}) *Error {
+	return newError(KindConflict, code, format, args...)
+}
+
+func Validation(code, format string, args ...interface{}) *Error {
+	return newError(KindValidation, code, format, args...)
+}
+
+func Forbidden(code, format string, args ...interface{}) *Error {
+	return newError(KindForbidden, code, format, args...)
+}
+
+func PreconditionFailed(code, format string, args ...interface{}) *Error {
+	return newError(KindPreconditionFailed, code, format, args...)
+}
+
+func PreconditionRequired(code, format string, args ...interface{}) *Error {
+	return newError(KindPreconditionRequired, code, format, args...)
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound reports whether err is a NotFound error.
+func IsNotFound(err error) bool {
+	appErr, ok := As(err)
+	return ok && appErr.Kind == KindNotFound
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// apiKeyPrefix marks API keys so they are easy to tell apart from tokens and to spot in leaks.
+const apiKeyPrefix = "sck_"
+
+// NewAPIKey returns a new random API key. Only its hash is ever stored.
+func NewAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("could not generate API key: %w", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key, which is what the database keeps.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseRole returns the role with the given name.
+func ParseRole(name string) (Role, bool) {
+	role := Role(name)
+	_, ok := rolePermissions[role]
+	return role, ok
+}
@@ -0,0 +1,74 @@
+// Package auth describes who is making a request and what they may do.
+package auth
+
+import (
+	"context"
+	"devTodTestTask/internal/apperr"
+)
+
+type Permission string
+
+const (
+	// PermCatSalary allows changing a cat's salary.
+	PermCatSalary Permission = "cats:salary"
+	// PermCatProfile allows changing a cat's name, experience and breed.
+	PermCatProfile Permission = "cats:profile"
+	// PermDeletedRead allows listing soft-deleted cats, missions and targets.
+	PermDeletedRead Permission = "deleted:read"
+	// PermDeletedRestore allows restoring soft-deleted cats, missions and targets.
+	PermDeletedRestore Permission = "deleted:restore"
+)
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleOperator Role = "operator"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:    {PermCatSalary, PermCatProfile, PermDeletedRead, PermDeletedRestore},
+	RoleOperator: {PermCatSalary},
+}
+
+// Principal is the caller of a request.
+type Principal struct {
+	Subject string
+	Role    Role
+}
+
+// Anonymous is used for requests that carry no credentials.
+var Anonymous = Principal{Subject: "anonymous", Role: RoleOperator}
+
+// Can reports whether the principal's role grants the permission.
+func (p Principal) Can(perm Permission) bool {
+	for _, granted := range rolePermissions[p.Role] {
+		if granted == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Require returns a Forbidden error unless the principal in ctx has the permission.
+func Require(ctx context.Context, perm Permission) error {
+	p := FromContext(ctx)
+	if !p.Can(perm) {
+		return apperr.Forbidden("permission_denied", "role %s lacks the %s permission", p.Role, perm)
+	}
+	return nil
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, or Anonymous.
+func FromContext(ctx context.Context) Principal {
+	if p, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return p
+	}
+	return Anonymous
+}
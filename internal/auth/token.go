@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"devTodTestTask/internal/apperr"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenConfig locates the keys JWTs are verified with. Either key file may be
+// empty to disable that algorithm.
+type TokenConfig struct {
+	// HS256KeyFile holds the shared HMAC secret.
+	HS256KeyFile string
+	// RS256PublicKeyFile holds the PEM encoded RSA public key of the token issuer.
+	RS256PublicKeyFile string
+	// Issuer and Audience, when set, must match the token's iss and aud claims.
+	Issuer   string
+	Audience string
+}
+
+// TokenVerifier checks HS256 and RS256 signed JWTs against local key files.
+type TokenVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	parser    *jwt.Parser
+}
+
+// tokenClaims are the claims read from a JWT. The subject becomes the
+// principal's subject and role its role, operator when absent.
+type tokenClaims struct {
+	Role string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func NewTokenVerifier(cfg TokenConfig) (*TokenVerifier, error) {
+	v := &TokenVerifier{}
+	var methods []string
+
+	if cfg.HS256KeyFile != "" {
+		secret, err := os.ReadFile(cfg.HS256KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read HS256 key: %w", err)
+		}
+		v.secret = []byte(strings.TrimSpace(string(secret)))
+		if len(v.secret) == 0 {
+			return nil, fmt.Errorf("HS256 key file %s is empty", cfg.HS256KeyFile)
+		}
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if cfg.RS256PublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.RS256PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read RS256 public key: %w", err)
+		}
+		if v.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+			return nil, fmt.Errorf("could not parse RS256 public key: %w", err)
+		}
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(options...)
+	return v, nil
+}
+
+// Enabled reports whether any key was configured.
+func (v *TokenVerifier) Enabled() bool {
+	return v != nil && (v.secret != nil || v.publicKey != nil)
+}
+
+// Verify checks a token's signature and claims and returns its principal.
+func (v *TokenVerifier) Verify(token string) (Principal, error) {
+	if !v.Enabled() {
+		return Principal{}, apperr.Unauthorized("tokens_disabled", "bearer tokens are not accepted by this server")
+	}
+
+	var claims tokenClaims
+	_, err := v.parser.ParseWithClaims(token, &claims, v.key)
+	if err != nil {
+		return Principal{}, apperr.Unauthorized("invalid_token", "invalid token: %v", err)
+	}
+	if claims.Subject == "" {
+		return Principal{}, apperr.Unauthorized("invalid_token", "invalid token: sub claim is required")
+	}
+
+	role := RoleOperator
+	if claims.Role != "" {
+		var ok bool
+		if role, ok = ParseRole(claims.Role); !ok {
+			return Principal{}, apperr.Unauthorized("invalid_token", "invalid token: unknown role %q", claims.Role)
+		}
+	}
+	return Principal{Subject: claims.Subject, Role: role}, nil
+}
+
+func (v *TokenVerifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method {
+	case jwt.SigningMethodHS256:
+		return v.secret, nil
+	case jwt.SigningMethodRS256:
+		return v.publicKey, nil
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// TokenIssuer signs JWTs that a TokenVerifier holding the matching key accepts.
+type TokenIssuer struct {
+	method   jwt.SigningMethod
+	key      interface{}
+	issuer   string
+	audience string
+}
+
+// NewTokenIssuer signs with the HS256 secret or the PEM encoded RS256 private
+// key in the given file; exactly one of them must be set. Issuer and audience,
+// when set, go into the iss and aud claims.
+func NewTokenIssuer(hs256KeyFile, rs256PrivateKeyFile, issuer, audience string) (*TokenIssuer, error) {
+	i := &TokenIssuer{issuer: issuer, audience: audience}
+	switch {
+	case hs256KeyFile != "" && rs256PrivateKeyFile != "":
+		return nil, fmt.Errorf("set either an HS256 key or an RS256 private key, not both")
+	case hs256KeyFile != "":
+		secret, err := os.ReadFile(hs256KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read HS256 key: %w", err)
+		}
+		key := []byte(strings.TrimSpace(string(secret)))
+		if len(key) == 0 {
+			return nil, fmt.Errorf("HS256 key file %s is empty", hs256KeyFile)
+		}
+		i.method, i.key = jwt.SigningMethodHS256, key
+	case rs256PrivateKeyFile != "":
+		pem, err := os.ReadFile(rs256PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read RS256 private key: %w", err)
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse RS256 private key: %w", err)
+		}
+		i.method, i.key = jwt.SigningMethodRS256, key
+	default:
+		return nil, fmt.Errorf("an HS256 key or an RS256 private key is required")
+	}
+	return i, nil
+}
+
+// Issue returns a signed token for the principal that expires after ttl.
+func (i *TokenIssuer) Issue(p Principal, ttl time.Duration) (string, error) {
+	if p.Subject == "" {
+		return "", fmt.Errorf("a subject is required")
+	}
+	if _, ok := ParseRole(string(p.Role)); !ok {
+		return "", fmt.Errorf("unknown role %q", p.Role)
+	}
+	if ttl <= 0 {
+		return "", fmt.Errorf("the token lifetime must be positive")
+	}
+
+	now := time.Now()
+	claims := tokenClaims{
+		Role: string(p.Role),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   p.Subject,
+			Issuer:    i.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	if i.audience != "" {
+		claims.Audience = jwt.ClaimStrings{i.audience}
+	}
+	return jwt.NewWithClaims(i.method, claims).SignedString(i.key)
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestIssuedTokensVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	secret := writeFile(t, "hs256.key", []byte("shared-secret\n"))
+	private := writeFile(t, "rs256.pem", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	public := writeFile(t, "rs256.pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+
+	verifier, err := NewTokenVerifier(TokenConfig{HS256KeyFile: secret, RS256PublicKeyFile: public, Issuer: "spy-cats", Audience: "api"})
+	if err != nil {
+		t.Fatalf("NewTokenVerifier: %v", err)
+	}
+
+	tests := []struct {
+		name                      string
+		hs256, rs256, issuer, aud string
+		wantErr                   bool
+	}{
+		{name: "HS256", hs256: secret, issuer: "spy-cats", aud: "api"},
+		{name: "RS256", rs256: private, issuer: "spy-cats", aud: "api"},
+		{name: "wrong issuer", hs256: secret, issuer: "someone", aud: "api", wantErr: true},
+		{name: "wrong audience", rs256: private, issuer: "spy-cats", aud: "web", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, err := NewTokenIssuer(tt.hs256, tt.rs256, tt.issuer, tt.aud)
+			if err != nil {
+				t.Fatalf("NewTokenIssuer: %v", err)
+			}
+			token, err := issuer.Issue(Principal{Subject: "ci", Role: RoleAdmin}, time.Minute)
+			if err != nil {
+				t.Fatalf("Issue: %v", err)
+			}
+
+			principal, err := verifier.Verify(token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Verify accepted a token for %s", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if principal != (Principal{Subject: "ci", Role: RoleAdmin}) {
+				t.Errorf("principal = %+v, want ci as admin", principal)
+			}
+		})
+	}
+}
+
+func TestNewTokenIssuerNeedsOneKey(t *testing.T) {
+	secret := writeFile(t, "hs256.key", []byte("shared-secret"))
+	if _, err := NewTokenIssuer("", "", "", ""); err == nil {
+		t.Error("NewTokenIssuer accepted no key")
+	}
+	if _, err := NewTokenIssuer(secret, secret, "", ""); err == nil {
+		t.Error("NewTokenIssuer accepted two keys")
+	}
+}
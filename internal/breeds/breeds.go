@@ -0,0 +1,47 @@
+// Package breeds validates cat breeds against TheCatAPI, falling back to a
+// local catalog table when the remote API cannot be reached.
+package breeds
+
+import (
+	"context"
+	"devTodTestTask/internal/apperr"
+	"errors"
+	"strings"
+)
+
+type Breed struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Validator checks a breed name and returns the breed it refers to, with
+// the canonical name spelled as the provider does.
+type Validator interface {
+	Validate(ctx context.Context, name string) (*Breed, error)
+}
+
+var (
+	// ErrUnknownBreed is returned when a provider does not know the breed.
+	ErrUnknownBreed = apperr.Validation("invalid_breed", "invalid breed")
+	// ErrUnavailable is returned when a provider could not be reached.
+	ErrUnavailable = errors.New("breed provider unavailable")
+)
+
+// Fallback asks Primary first and Secondary only when Primary is unavailable.
+type Fallback struct {
+	Primary   Validator
+	Secondary Validator
+}
+
+func (f *Fallback) Validate(ctx context.Context, name string) (*Breed, error) {
+	breed, err := f.Primary.Validate(ctx, name)
+	if errors.Is(err, ErrUnavailable) {
+		return f.Secondary.Validate(ctx, name)
+	}
+	return breed, err
+}
+
+// normalize is the form breed names are compared and cached in.
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,48 @@
+package breeds
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache remembers breeds a Validator accepted for TTL. Rejections and
+// failures are not cached.
+type Cache struct {
+	Next Validator
+	TTL  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	breed     Breed
+	expiresAt time.Time
+}
+
+func NewCache(next Validator, ttl time.Duration) *Cache {
+	return &Cache{Next: next, TTL: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *Cache) Validate(ctx context.Context, name string) (*Breed, error) {
+	key := normalize(name)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		breed := entry.breed
+		return &breed, nil
+	}
+
+	breed, err := c.Next.Validate(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cacheEntry{breed: *breed, expiresAt: time.Now().Add(c.TTL)}
+	c.mu.Unlock()
+	return breed, nil
+}
@@ -0,0 +1,73 @@
+package breeds
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// bundledCatalog is the breed list shipped with the binary, used to seed the breeds table.
+//
+//go:embed catalog.json
+var bundledCatalog []byte
+
+// Catalog validates breeds against the local breeds table.
+type Catalog struct {
+	DB *sql.DB
+}
+
+func (c *Catalog) Validate(ctx context.Context, name string) (*Breed, error) {
+	var breed Breed
+	query := `SELECT id, name FROM breeds WHERE LOWER(name) = $1`
+	err := c.DB.QueryRowContext(ctx, query, normalize(name)).Scan(&breed.ID, &breed.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUnknownBreed
+		}
+		return nil, fmt.Errorf("could not look up breed: %w", err)
+	}
+	return &breed, nil
+}
+
+func (c *Catalog) List(ctx context.Context) ([]Breed, error) {
+	rows, err := c.DB.QueryContext(ctx, `SELECT id, name FROM breeds ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("could not list breeds: %w", err)
+	}
+	defer rows.Close()
+
+	breeds := []Breed{}
+	for rows.Next() {
+		var breed Breed
+		if err := rows.Scan(&breed.ID, &breed.Name); err != nil {
+			return nil, fmt.Errorf("could not scan breed: %w", err)
+		}
+		breeds = append(breeds, breed)
+	}
+	return breeds, rows.Err()
+}
+
+// Seed inserts the bundled breeds that are not in the table yet.
+func (c *Catalog) Seed(ctx context.Context) error {
+	var breeds []Breed
+	if err := json.Unmarshal(bundledCatalog, &breeds); err != nil {
+		return fmt.Errorf("could not parse bundled breeds: %w", err)
+	}
+
+	tx, err := c.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, breed := range breeds {
+		_, err := tx.ExecContext(ctx, `INSERT INTO breeds (id, name) VALUES ($1, $2) ON CONFLICT DO NOTHING`, breed.ID, breed.Name)
+		if err != nil {
+			return fmt.Errorf("could not seed breed %s: %w", breed.ID, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,33 @@
+package breeds
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// The seed migration must list every bundled breed, so that cats are
+// normalized against the same catalog the app validates with.
+func TestSeedMigrationMatchesBundledCatalog(t *testing.T) {
+	var bundled []Breed
+	if err := json.Unmarshal(bundledCatalog, &bundled); err != nil {
+		t.Fatalf("parsing bundled catalog: %v", err)
+	}
+
+	migration, err := os.ReadFile("../../migrations/0016_seed_breeds.up.sql")
+	if err != nil {
+		t.Fatalf("reading seed migration: %v", err)
+	}
+	for _, breed := range bundled {
+		row := fmt.Sprintf("(%s, %s)", sqlString(breed.ID), sqlString(breed.Name))
+		if !strings.Contains(string(migration), row) {
+			t.Errorf("seed migration lacks %s", row)
+		}
+	}
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,101 @@
+package breeds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultTheCatAPIURL = "https://api.thecatapi.com"
+
+// TheCatAPI validates breeds with the breed search of api.thecatapi.com or any
+// server exposing the same /v1/breeds/search endpoint.
+type TheCatAPI struct {
+	BaseURL string
+	Client  *http.Client
+	// Retries is how many times a failed request is repeated after the first attempt.
+	Retries    int
+	RetryDelay time.Duration
+}
+
+func NewTheCatAPI(baseURL string, timeout time.Duration, retries int) *TheCatAPI {
+	return &TheCatAPI{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Client:     &http.Client{Timeout: timeout},
+		Retries:    retries,
+		RetryDelay: 200 * time.Millisecond,
+	}
+}
+
+func (api *TheCatAPI) Validate(ctx context.Context, name string) (*Breed, error) {
+	var found []Breed
+	if err := api.get(ctx, "/v1/breeds/search?name="+url.QueryEscape(strings.TrimSpace(name)), &found); err != nil {
+		return nil, err
+	}
+
+	// The search also returns partial matches; like the catalog, only an exact name is accepted
+	for i := range found {
+		if normalize(found[i].Name) == normalize(name) {
+			return &found[i], nil
+		}
+	}
+	if len(found) == 0 {
+		return nil, ErrUnknownBreed
+	}
+
+	candidates := make([]string, len(found))
+	for i, breed := range found {
+		candidates[i] = breed.Name
+	}
+	return nil, ErrUnknownBreed.WithDetails(map[string]interface{}{"candidates": candidates})
+}
+
+// get fetches path and decodes the JSON body into out, retrying transport
+// errors and 5xx responses. Every failure is reported as ErrUnavailable.
+func (api *TheCatAPI) get(ctx context.Context, path string, out interface{}) error {
+	var err error
+	for attempt := 0; attempt <= api.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %v", ErrUnavailable, ctx.Err())
+			case <-time.After(api.RetryDelay * time.Duration(attempt)):
+			}
+		}
+
+		var retry bool
+		retry, err = api.do(ctx, path, out)
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			break
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
+
+func (api *TheCatAPI) do(ctx context.Context, path string, out interface{}) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.BaseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := api.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to fetch breed data: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return res.StatusCode >= http.StatusInternalServerError, fmt.Errorf("breed API responded with %s", res.Status)
+	}
+	if err = json.NewDecoder(res.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode breed data: %w", err)
+	}
+	return false, nil
+}
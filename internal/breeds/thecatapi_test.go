@@ -0,0 +1,88 @@
+package breeds
+
+import (
+	"context"
+	"devTodTestTask/internal/apperr"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubSearch mimics /v1/breeds/search, which matches any breed whose name contains the query.
+func stubSearch(t *testing.T, catalog []Breed) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := strings.ToLower(r.URL.Query().Get("name"))
+		found := []Breed{}
+		for _, breed := range catalog {
+			if strings.Contains(strings.ToLower(breed.Name), query) {
+				found = append(found, breed)
+			}
+		}
+		json.NewEncoder(w).Encode(found)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTheCatAPIValidate(t *testing.T) {
+	server := stubSearch(t, []Breed{
+		{ID: "siam", Name: "Siamese"},
+		{ID: "bali", Name: "Balinese"},
+		{ID: "beng", Name: "Bengal"},
+	})
+	api := NewTheCatAPI(server.URL, time.Second, 0)
+
+	tests := []struct {
+		name   string
+		wantID string
+	}{
+		{"Siamese", "siam"},
+		{" SIAMESE ", "siam"},
+		{"bengal", "beng"},
+		// Partial matches are rejected like they are by the local catalog
+		{"Siam", ""},
+		{"nese", ""},
+		{"Sphynx", ""},
+	}
+	for _, tt := range tests {
+		breed, err := api.Validate(context.Background(), tt.name)
+		if tt.wantID == "" {
+			if appErr, ok := apperr.As(err); !ok || appErr.Code != "invalid_breed" {
+				t.Errorf("Validate(%q) = %v, %v; want invalid_breed", tt.name, breed, err)
+			}
+			continue
+		}
+		if err != nil || breed.ID != tt.wantID {
+			t.Errorf("Validate(%q) = %v, %v; want %s", tt.name, breed, err, tt.wantID)
+		}
+	}
+}
+
+func TestTheCatAPIUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	fallback := &Fallback{
+		Primary:   &TheCatAPI{BaseURL: server.URL, Client: server.Client(), Retries: 1},
+		Secondary: stubValidator{"siamese": {ID: "siam", Name: "Siamese"}},
+	}
+	breed, err := fallback.Validate(context.Background(), "siamese")
+	if err != nil || breed.ID != "siam" {
+		t.Fatalf("Validate = %v, %v; want the secondary's siam", breed, err)
+	}
+}
+
+type stubValidator map[string]Breed
+
+func (v stubValidator) Validate(ctx context.Context, name string) (*Breed, error) {
+	breed, ok := v[normalize(name)]
+	if !ok {
+		return nil, ErrUnknownBreed
+	}
+	return &breed, nil
+}
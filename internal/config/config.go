@@ -2,14 +2,96 @@ package config
 
 import (
 	"database/sql"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/breeds"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	_ "github.com/lib/pq"
 )
 
 var DB *sql.DB
 
+// Config holds the application settings read from the environment.
+type Config struct {
+	// RequestTimeout bounds how long a request may spend in the service and data layers.
+	RequestTimeout time.Duration
+
+	// BreedAPIURL is the base URL of TheCatAPI, or of a local stub serving /v1/breeds/search.
+	BreedAPIURL     string
+	BreedAPITimeout time.Duration
+	BreedAPIRetries int
+	// BreedCacheTTL is how long an accepted breed is remembered.
+	BreedCacheTTL time.Duration
+
+	// PurgeRetention is how long soft-deleted rows are kept before the purge job removes them.
+	PurgeRetention time.Duration
+	PurgeInterval  time.Duration
+
+	// IdempotencyWindow is how long the response to an Idempotency-Key is replayed.
+	IdempotencyWindow time.Duration
+
+	// Tokens locates the keys bearer JWTs are verified with; without any, only API keys are accepted.
+	Tokens auth.TokenConfig
+}
+
+func Load() Config {
+	return Config{
+		RequestTimeout:  durationEnv("REQUEST_TIMEOUT", 10*time.Second),
+		BreedAPIURL:     stringEnv("BREED_API_URL", breeds.DefaultTheCatAPIURL),
+		BreedAPITimeout: durationEnv("BREED_API_TIMEOUT", 3*time.Second),
+		BreedAPIRetries: intEnv("BREED_API_RETRIES", 2),
+		BreedCacheTTL:   durationEnv("BREED_CACHE_TTL", time.Hour),
+		PurgeRetention:  durationEnv("PURGE_RETENTION", 30*24*time.Hour),
+		PurgeInterval:   durationEnv("PURGE_INTERVAL", time.Hour),
+
+		IdempotencyWindow: durationEnv("IDEMPOTENCY_WINDOW", 24*time.Hour),
+
+		Tokens: auth.TokenConfig{
+			HS256KeyFile:       os.Getenv("JWT_HS256_KEY_FILE"),
+			RS256PublicKeyFile: os.Getenv("JWT_RS256_PUBLIC_KEY_FILE"),
+			Issuer:             os.Getenv("JWT_ISSUER"),
+			Audience:           os.Getenv("JWT_AUDIENCE"),
+		},
+	}
+}
+
+func stringEnv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+func intEnv(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		fmt.Printf("Invalid %s %q, using %d\n", key, value, def)
+		return def
+	}
+	return n
+}
+
+// durationEnv parses a duration such as "5s" from the environment, falling back to def.
+func durationEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		fmt.Printf("Invalid %s %q, using %s\n", key, value, def)
+		return def
+	}
+	return d
+}
+
 func ConnectDB() *sql.DB {
 	var err error
 	connStr := fmt.Sprintf(
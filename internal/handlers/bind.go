@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	// Report fields by their JSON or query name rather than the Go field name
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+
+		// target_notes keeps the notes limit in one place, models.MaxTargetNotesLength
+		v.RegisterAlias("target_notes", "max="+strconv.Itoa(models.MaxTargetNotesLength))
+	}
+}
+
+// invalidInput converts a body binding error into an error for the client.
+// Malformed JSON is a BadRequest; well-formed bodies with wrongly typed or
+// invalid values are a Validation error listing every failing field.
+func invalidInput(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return apperr.Validation("invalid_input", "invalid input").
+			WithFields(apperr.FieldError{Field: typeErr.Field, Message: typeMessage(typeErr.Type)})
+	}
+
+	fields, ok := fieldErrors(err)
+	if !ok {
+		return apperr.BadRequest("invalid_input", "invalid input: %v", err)
+	}
+	return apperr.Validation("invalid_input", "invalid input").WithFields(fields...)
+}
+
+// invalidQuery converts a query binding error into a BadRequest error listing every failing parameter.
+func invalidQuery(err error) error {
+	fields, ok := fieldErrors(err)
+	if !ok {
+		return apperr.BadRequest("invalid_query", "invalid query: %v", err)
+	}
+	return apperr.BadRequest("invalid_query", "invalid query").WithFields(fields...)
+}
+
+func fieldErrors(err error) ([]apperr.FieldError, bool) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+
+	fields := make([]apperr.FieldError, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, apperr.FieldError{Field: fieldPath(fieldErr), Message: fieldMessage(fieldErr)})
+	}
+	return fields, true
+}
+
+// fieldPath returns the field's JSON path without the request type, e.g. "targets[0].country".
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	// ActualTag looks through aliases such as target_notes
+	switch fieldErr.ActualTag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fieldErr.Kind() == reflect.String && fieldErr.Param() == "1" {
+			return "must not be empty"
+		}
+		if fieldErr.Kind() == reflect.String {
+			return "must be at least " + fieldErr.Param() + " characters long"
+		}
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at most " + fieldErr.Param() + " characters long"
+		}
+		return "must be at most " + fieldErr.Param()
+	case "oneof":
+		return "must be one of: " + fieldErr.Param()
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code such as UA"
+	default:
+		return fmt.Sprintf("failed %q validation", fieldErr.Tag())
+	}
+}
+
+func typeMessage(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "must be an integer"
+	case reflect.Float32, reflect.Float64:
+		return "must be a number"
+	case reflect.Bool:
+		return "must be a boolean"
+	case reflect.String:
+		return "must be a string"
+	case reflect.Slice, reflect.Array:
+		return "must be an array"
+	default:
+		return "must be an object"
+	}
+}
+
+// pathID parses a positive integer path parameter.
+func pathID(c *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		return 0, apperr.BadRequest("invalid_id", "invalid %s %q", name, c.Param(name))
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"devTodTestTask/internal/models"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestTargetNotesLimit(t *testing.T) {
+	notes := func(n int) string { return strings.Repeat("ü", n) }
+	requests := map[string]func(string) interface{}{
+		"CreateTargetRequest": func(s string) interface{} {
+			return &CreateTargetRequest{Name: "Target", Country: "UA", Notes: s}
+		},
+		"UpdateTargetNotesRequest": func(s string) interface{} { return &UpdateTargetNotesRequest{ID: 1, Notes: s} },
+		"TargetNotesRequest":       func(s string) interface{} { return &TargetNotesRequest{Notes: s} },
+		"CreateTargetNoteRequest": func(s string) interface{} {
+			return &CreateTargetNoteRequest{AuthorCatID: 1, Body: s}
+		},
+	}
+
+	limit := strconv.Itoa(models.MaxTargetNotesLength)
+	for name, request := range requests {
+		t.Run(name, func(t *testing.T) {
+			if err := binding.Validator.ValidateStruct(request(notes(models.MaxTargetNotesLength))); err != nil {
+				t.Errorf("notes at the limit rejected: %v", err)
+			}
+
+			err := binding.Validator.ValidateStruct(request(notes(models.MaxTargetNotesLength + 1)))
+			fields, ok := fieldErrors(err)
+			if !ok || len(fields) != 1 || fields[0].Message != "must be at most "+limit+" characters long" {
+				t.Errorf("notes over the limit = %v, want one field error naming the limit", err)
+			}
+
+			// The documented limit must follow the validated one
+			typ := reflect.TypeOf(request("")).Elem()
+			for i := 0; i < typ.NumField(); i++ {
+				if tag, ok := typ.Field(i).Tag.Lookup("maxLength"); ok && strings.Contains(typ.Field(i).Tag.Get("binding"), "target_notes") && tag != limit {
+					t.Errorf("%s maxLength = %s, want %s", typ.Field(i).Name, tag, limit)
+				}
+			}
+		})
+	}
+}
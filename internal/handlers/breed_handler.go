@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"devTodTestTask/internal/breeds"
+	"devTodTestTask/internal/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type BreedHandler struct {
+	Service *services.BreedService
+}
+
+// ListBreedsHandler godoc
+// @Summary Get list of breeds
+// @Description Get the catalog of breeds accepted for cats, with their canonical names
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} BreedListResponse "List of breeds"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /breeds [get]
+// @Router /v2/breeds [get]
+func (h *BreedHandler) ListBreedsHandler(c *gin.Context) {
+	list, err := h.Service.ListBreeds(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, BreedListResponse{Data: list})
+}
+
+type BreedListResponse struct {
+	Data []breeds.Breed `json:"data"`
+}
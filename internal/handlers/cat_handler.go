@@ -3,10 +3,8 @@ package handlers
 import (
 	"devTodTestTask/internal/models"
 	"devTodTestTask/internal/services"
-	"devTodTestTask/internal/utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"strconv"
 )
 
 type CatHandler struct {
@@ -16,109 +14,367 @@ type CatHandler struct {
 // CreateCatHandler godoc
 // @Summary Create a new cat
 // @Description Create a new cat and store it in the database
-// @Param cat body models.Cat true "Cat data"
-// @Success 201 {object} models.Cat "Successfully created cat"
-// @Failure 400 {object} ErrorResponse "Invalid input"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Param cat body CreateCatRequest true "Cat data"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 201 {object} CatView "Successfully created cat"
+// @Header 201 {string} ETag "Version of the cat"
+// @Header 201 {string} Idempotent-Replayed "Set to true when the response is a replay"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 422 {object} Problem "Invalid fields or breed, or Idempotency-Key reused for a different request"
+// @Failure 409 {object} Problem "A request with the same Idempotency-Key is still being processed"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /cats [post]
+// @Router /v2/cats [post]
 func (h *CatHandler) CreateCatHandler(c *gin.Context) {
-	var cat models.Cat
-	if err := c.ShouldBindJSON(&cat); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid input"})
+	var req CreateCatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
 		return
 	}
 
-	if err := utils.ValidateBreed(cat.Breed); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid breed"})
+	cat := req.Cat()
+	if err := h.Service.CreateCat(c.Request.Context(), &cat); err != nil {
+		c.Error(err)
 		return
 	}
-
-	if err := h.Service.CreateCat(&cat); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
-	}
-	c.JSON(http.StatusCreated, cat)
+	setETag(c, cat.Version)
+	c.JSON(http.StatusCreated, newCatView(&cat))
 }
 
 // ListCatsHandler godoc
-// @Summary Get list of all cats
-// @Description Get a list of all cats in the database
-// @Success 200 {array} models.Cat "List of cats"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Summary Get list of cats
+// @Description Get a paginated list of cats filtered by breed, experience and salary
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of cats to skip"
+// @Param breed query string false "Breed name (case-insensitive)"
+// @Param min_experience query int false "Minimum experience"
+// @Param max_experience query int false "Maximum experience"
+// @Param min_salary query number false "Minimum salary"
+// @Param max_salary query number false "Maximum salary"
+// @Param sort_by query string false "Sort column" Enums(id, name, experience, breed, salary, created_at, updated_at)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param include_deleted query bool false "Also list soft-deleted cats (admins only)"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} CatListResponse "Page of cats"
+// @Failure 400 {object} Problem "Invalid query parameters"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Not allowed to list deleted cats"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /cats [get]
+// @Router /v2/cats [get]
 func (h *CatHandler) ListCatsHandler(c *gin.Context) {
-	cats, err := h.Service.ListCats()
+	var filter models.CatFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+	filter.Normalize()
+
+	cats, total, err := h.Service.ListCats(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, cats)
+	c.JSON(http.StatusOK, CatListResponse{Data: newCatViews(cats), Total: total, Limit: filter.Limit, Offset: filter.Offset})
 }
 
 // CatByIDHandler godoc
 // @Summary Get cat by ID
 // @Description Get a specific cat by its ID
 // @Param id path int true "Cat ID"
-// @Success 200 {object} models.Cat "Cat data"
-// @Failure 400 {object} ErrorResponse "Invalid ID format"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} CatView "Cat data"
+// @Header 200 {string} ETag "Version of the cat"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Cat not found"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /cats/{id} [get]
+// @Router /v2/cats/{id} [get]
 func (h *CatHandler) CatByIDHandler(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	cats, err := h.Service.CatByID(uint(id))
+	id, err := pathID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, cats)
+
+	cat, err := h.Service.CatByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, cat.Version)
+	c.JSON(http.StatusOK, newCatView(cat))
 }
 
 // UpdateCatHandler godoc
-// @Summary Update a cat
-// @Description Update an existing cat's details
-// @Param cat body models.Cat true "Updated cat data"
-// @Success 200 {object} ErrorResponse "Successfully updated cat"
-// @Failure 400 {object} ErrorResponse "Invalid input"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Summary Update a cat's salary
+// @Description Update an existing cat's salary. Deprecated, use PATCH /v2/cats/{id}.
+// @Deprecated
+// @Param cat body UpdateCatSalaryRequest true "Cat ID and new salary"
+// @Param If-Match header string true "ETag of the cat being changed"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Successfully updated cat"
+// @Header 200 {string} ETag "New version of the cat"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Not allowed to edit the salary"
+// @Failure 404 {object} Problem "Cat not found"
+// @Failure 412 {object} Problem "Cat was changed since it was read"
+// @Failure 422 {object} Problem "Invalid fields"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /cats [put]
 func (h *CatHandler) UpdateCatHandler(c *gin.Context) {
-	var cat models.Cat
-	if err := c.ShouldBindJSON(&cat); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid input"})
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req UpdateCatSalaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	cat, err := h.Service.PatchCat(c.Request.Context(), req.ID, version, models.CatPatch{Salary: &req.Salary})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, cat.Version)
+	c.JSON(http.StatusOK, MessageResponse{Message: "Successfully updated cat"})
+}
+
+// PatchCatHandler godoc
+// @Summary Partially update a cat
+// @Description Apply a JSON Merge Patch (RFC 7386) to a cat. Salary is editable by operators; name, experience and breed need the cats:profile permission.
+// @Accept json
+// @Param id path int true "Cat ID"
+// @Param patch body models.CatPatch true "Fields to change"
+// @Param If-Match header string true "ETag of the cat being changed"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} CatView "Updated cat"
+// @Header 200 {string} ETag "New version of the cat"
+// @Failure 400 {object} Problem "Invalid ID or malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Not allowed to edit some fields"
+// @Failure 404 {object} Problem "Cat not found"
+// @Failure 412 {object} Problem "Cat was changed since it was read"
+// @Failure 422 {object} Problem "Invalid field values or breed"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /cats/{id} [patch]
+// @Router /v2/cats/{id} [patch]
+func (h *CatHandler) PatchCatHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	patch, err := decodeCatPatch(c.Request.Body)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := h.Service.UpdateCat(&cat)
+	cat, err := h.Service.PatchCat(c.Request.Context(), id, version, *patch)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, ErrorResponse{Error: "Successfully updated cat"})
+	setETag(c, cat.Version)
+	c.JSON(http.StatusOK, newCatView(cat))
 }
 
 // DeleteCatHandler godoc
 // @Summary Delete a cat
-// @Description Delete a cat from the database by its ID
-// @Param cat body models.Cat true "Deleted cat data"
-// @Success 200 {object} ErrorResponse "Successfully deleted cat"
-// @Failure 400 {object} ErrorResponse "Invalid ID format"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Description Delete a cat from the database by its ID. Deprecated, use DELETE /v2/cats/{id}.
+// @Deprecated
+// @Param cat body DeleteCatRequest true "ID of the cat to delete"
+// @Param reassign_to query int false "Hand the cat's active mission over to this cat"
+// @Param unassign query bool false "Return the cat's active mission to the unassigned pool"
+// @Param If-Match header string true "ETag of the cat being deleted"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Successfully deleted cat"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Cat not found"
+// @Failure 409 {object} Problem "Cat has an active mission, or the new cat already has one"
+// @Failure 412 {object} Problem "Cat was changed since it was read"
+// @Failure 422 {object} Problem "Invalid ID or retirement options"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /cats [delete]
 func (h *CatHandler) DeleteCatHandler(c *gin.Context) {
-	var cat models.Cat
-	if err := c.ShouldBindJSON(&cat); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid input"})
+	var retirement models.CatRetirement
+	if err := c.ShouldBindQuery(&retirement); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req DeleteCatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	err = h.Service.DeleteCat(c.Request.Context(), &models.Cat{ID: int(req.ID), Version: version}, retirement)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "Successfully deleted cat"})
+}
+
+// DeleteCatByIDHandler godoc
+// @Summary Delete a cat
+// @Description Delete a cat by its ID. A cat on an active mission is only deleted together with reassign_to or unassign=true, which hand the mission over in the same transaction.
+// @Param id path int true "Cat ID"
+// @Param reassign_to query int false "Hand the cat's active mission over to this cat"
+// @Param unassign query bool false "Return the cat's active mission to the unassigned pool"
+// @Param If-Match header string true "ETag of the cat being deleted"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 204 "Successfully deleted cat"
+// @Failure 400 {object} Problem "Invalid ID format or query"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Cat not found"
+// @Failure 409 {object} Problem "Cat has an active mission, or the new cat already has one"
+// @Failure 412 {object} Problem "Cat was changed since it was read"
+// @Failure 422 {object} Problem "Invalid retirement options"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/cats/{id} [delete]
+func (h *CatHandler) DeleteCatByIDHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var retirement models.CatRetirement
+	if err := c.ShouldBindQuery(&retirement); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+
+	if err := h.Service.DeleteCat(c.Request.Context(), &models.Cat{ID: int(id), Version: version}, retirement); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreCatHandler godoc
+// @Summary Restore a deleted cat
+// @Description Undo the soft delete of a cat (admins only)
+// @Param id path int true "Cat ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} CatView "Restored cat"
+// @Header 200 {string} ETag "Version of the cat"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Not allowed to restore"
+// @Failure 404 {object} Problem "Cat not found"
+// @Failure 409 {object} Problem "Cat is not deleted"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/cats/{id}/restore [post]
+func (h *CatHandler) RestoreCatHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := h.Service.DeleteCat(&cat)
+	cat, err := h.Service.RestoreCat(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, ErrorResponse{Error: "Successfully deleted cat"})
+	setETag(c, cat.Version)
+	c.JSON(http.StatusOK, newCatView(cat))
+}
+
+// CatHistoryHandler godoc
+// @Summary Get cat history
+// @Description Get the audit trail of a cat: changes of its fields and the missions it was assigned to or taken off, oldest first
+// @Param id path int true "Cat ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} HistoryResponse "Cat history"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Cat not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/cats/{id}/history [get]
+func (h *CatHandler) CatHistoryHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	events, err := h.Service.CatHistory(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, HistoryResponse{Data: newAuditEventViews(events)})
+}
+
+type CatListResponse struct {
+	Data   []CatView `json:"data"`
+	Total  int       `json:"total"`
+	Limit  int       `json:"limit"`
+	Offset int       `json:"offset"`
+}
+
+type CreateCatRequest struct {
+	Name       string  `json:"name" binding:"required,max=100"`
+	Experience int     `json:"experience" binding:"min=0"`
+	Breed      string  `json:"breed" binding:"required,max=100"`
+	Salary     float64 `json:"salary" binding:"min=0"`
+}
+
+func (r CreateCatRequest) Cat() models.Cat {
+	return models.Cat{Name: r.Name, Experience: r.Experience, Breed: r.Breed, Salary: r.Salary}
+}
+
+type UpdateCatSalaryRequest struct {
+	ID     uint    `json:"id" binding:"required"`
+	Salary float64 `json:"salary" binding:"min=0"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+type DeleteCatRequest struct {
+	ID uint `json:"id" binding:"required"`
 }
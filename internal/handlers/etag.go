@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"devTodTestTask/internal/apperr"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+// setETag sends the version of the resource in the response as its entity tag.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatch returns the version named by the If-Match header, or 0 when the
+// header is absent or "*" so that any version matches. Tags that are not one
+// of our versions can never match and fail the precondition.
+func ifMatch(c *gin.Context) (int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if unquoted, err := strconv.Unquote(tag); err == nil {
+		if version, err := strconv.Atoi(unquoted); err == nil && version > 0 {
+			return version, nil
+		}
+	}
+	return 0, apperr.PreconditionFailed("version_mismatch", "If-Match %s does not match the current version", header)
+}
@@ -5,7 +5,6 @@ import (
 	"devTodTestTask/internal/services"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"strconv"
 )
 
 type MissionHandler struct {
@@ -14,217 +13,897 @@ type MissionHandler struct {
 
 // CreateMissionHandler godoc
 // @Summary Create a new mission
-// @Description Create a new mission and store it in the database
-// @Param mission body models.Mission true "Mission data"
-// @Success 201 {object} models.Mission "Successfully created mission"
-// @Failure 400 {object} ErrorResponse "Invalid input"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Description Create a new mission and store it in the database. Without cat_id the mission is created as an unassigned draft.
+// @Param mission body CreateMissionRequest true "Mission data"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 201 {object} MissionView "Successfully created mission"
+// @Header 201 {string} ETag "Version of the mission"
+// @Header 201 {string} Idempotent-Replayed "Set to true when the response is a replay"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 422 {object} Problem "Invalid fields, not between 1 and 3 targets, or Idempotency-Key reused for a different request"
+// @Failure 404 {object} Problem "Cat not found"
+// @Failure 409 {object} Problem "Cat already has an active mission, or a request with the same Idempotency-Key is still being processed"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /missions [post]
+// @Router /v2/missions [post]
 func (h *MissionHandler) CreateMissionHandler(c *gin.Context) {
-	var mission models.Mission
-	if err := c.ShouldBindJSON(&mission); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	var req CreateMissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
 		return
 	}
 
-	if err := h.Service.CreateMission(&mission); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	mission := req.Mission()
+	if err := h.Service.CreateMission(c.Request.Context(), &mission); err != nil {
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusCreated, mission)
+	setETag(c, mission.Version)
+	c.JSON(http.StatusCreated, newMissionView(&mission))
 }
 
 // ListMissionsHandler godoc
-// @Summary Get list of all missions
-// @Description Get a list of all missions in the database
-// @Success 200 {array} models.Mission "List of missions"
-// @Failure 404 {object} ErrorResponse "Missions not found"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Summary Get list of missions
+// @Description Get a paginated list of missions with their targets
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of missions to skip"
+// @Param status query string false "Filter by status" Enums(draft, assigned, in_progress, completed, aborted)
+// @Param is_complete query bool false "Filter by completion"
+// @Param cat_id query int false "Filter by assigned cat"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created at or before (RFC 3339)"
+// @Param unassigned query bool false "Only missions without (true) or with (false) an assigned cat"
+// @Param include_deleted query bool false "Also list soft-deleted missions and targets (admins only)"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MissionListResponse "Page of missions"
+// @Failure 400 {object} Problem "Invalid query parameters"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Not allowed to list deleted missions"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /missions [get]
+// @Router /v2/missions [get]
 func (h *MissionHandler) ListMissionsHandler(c *gin.Context) {
-	missions, err := h.Service.ListMissions()
+	var filter models.MissionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(invalidQuery(err))
+		return
+	}
+	filter.Normalize()
+
+	missions, total, err := h.Service.ListMissions(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Missions not found"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": missions})
+	c.JSON(http.StatusOK, MissionListResponse{Data: newMissionViews(missions), Total: total, Limit: filter.Limit, Offset: filter.Offset})
 }
 
 // GetMissionByIDHandler godoc
 // @Summary Get mission by ID
 // @Description Get a specific mission by its ID
 // @Param id path int true "Mission ID"
-// @Success 200 {object} models.Mission "Mission data"
-// @Failure 404 {object} ErrorResponse "Mission not found"
-// @Failure 400 {object} ErrorResponse "Invalid ID format"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MissionResponse "Mission data"
+// @Header 200 {string} ETag "Version of the mission; targets carry their own version"
+// @Failure 404 {object} Problem "Mission not found"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /missions/{id} [get]
+// @Router /v2/missions/{id} [get]
 func (h *MissionHandler) GetMissionByIDHandler(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	mission, err := h.Service.GetMissionByID(uint(id))
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	mission, err := h.Service.GetMissionByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Mission not found"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": mission})
+	setETag(c, mission.Version)
+	c.JSON(http.StatusOK, MissionResponse{Data: newMissionView(mission)})
 }
 
 // UpdateMissionStatusHandler godoc
 // @Summary Update mission status
-// @Description Update the status of an existing mission.
-// @Param mission body models.Mission true "Updated mission data"
-// @Success 200 {object} ErrorResponse "Successfully updated mission status"
-// @Failure 400 {object} ErrorResponse "Invalid input"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Description Update the status of an existing mission. Deprecated, use POST /v2/missions/{id}/transitions.
+// @Deprecated
+// @Param mission body UpdateMissionStatusRequest true "Mission ID and completion flag"
+// @Param If-Match header string true "ETag of the mission being changed"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Successfully updated mission status"
+// @Header 200 {string} ETag "Version of the mission after the update"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 422 {object} Problem "Invalid fields"
+// @Failure 409 {object} Problem "Mission still has open targets"
+// @Failure 404 {object} Problem "Mission not found"
+// @Failure 412 {object} Problem "Mission was changed since it was read"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /missions [put]
 func (h *MissionHandler) UpdateMissionStatusHandler(c *gin.Context) {
-	var mission models.Mission
-	if err := c.ShouldBindJSON(&mission); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req UpdateMissionStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	mission := models.Mission{ID: req.ID, IsComplete: req.IsComplete, Version: version}
+	err = h.Service.UpdateMissionStatus(c.Request.Context(), &mission)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, mission.Version)
+	c.JSON(http.StatusOK, MessageResponse{Message: "Mission status updated successfully"})
+}
+
+// TransitionMissionHandler godoc
+// @Summary Transition a mission
+// @Description Move a mission to another lifecycle status (draft, assigned, in_progress, completed, aborted).
+// @Description A mission without a cat cannot become assigned or in_progress; assign a cat with PUT /v2/missions/{id}/cats/{cat_id}.
+// @Param id path int true "Mission ID"
+// @Param transition body MissionTransitionRequest true "Target status"
+// @Param If-Match header string false "ETag of the mission being changed"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MissionView "Mission after the transition"
+// @Header 200 {string} ETag "New version of the mission"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Mission not found"
+// @Failure 409 {object} Problem "Transition not allowed (details list the allowed statuses) or mission has no cat"
+// @Failure 412 {object} Problem "Mission was changed since it was read"
+// @Failure 422 {object} Problem "Unknown status"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /missions/{id}/transitions [post]
+// @Router /v2/missions/{id}/transitions [post]
+func (h *MissionHandler) TransitionMissionHandler(c *gin.Context) {
+	missionID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req MissionTransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
 		return
 	}
 
-	err := h.Service.UpdateMissionStatus(&mission)
+	mission, err := h.Service.TransitionMission(c.Request.Context(), missionID, version, req.Status)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Mission status updated successfully"})
+	setETag(c, mission.Version)
+	c.JSON(http.StatusOK, newMissionView(mission))
 }
 
 // DeleteMissionHandler godoc
 // @Summary Delete a mission
 // @Description Delete a mission by its ID
 // @Param id path int true "Mission ID"
-// @Success 200 {object} ErrorResponse "Successfully deleted mission"
-// @Failure 400 {object} ErrorResponse "Invalid ID format"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Param If-Match header string true "ETag of the mission being deleted"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Successfully deleted mission"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Mission not found"
+// @Failure 409 {object} Problem "Mission is assigned to a cat"
+// @Failure 412 {object} Problem "Mission was changed since it was read"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /missions/{id} [delete]
+// @Router /v2/missions/{id} [delete]
 func (h *MissionHandler) DeleteMissionHandler(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	err := h.Service.DeleteMission(uint(id))
+	version, err := ifMatch(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully deleted mission"})
+
+	if err := h.Service.DeleteMission(c.Request.Context(), id, version); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "Successfully deleted mission"})
 }
 
 // AddTargetToMissionHandler godoc
 // @Summary Add a target to a mission
 // @Description Add a target to a specific mission
-// @Param mission_id path int true "Mission ID"
-// @Param target body models.Target true "Target data"
-// @Success 201 {object} models.Target "Successfully added target to mission"
-// @Failure 400 {object} ErrorResponse "Invalid input"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /missions/{mission_id}/targets [post]
+// @Param id path int true "Mission ID"
+// @Param target body CreateTargetRequest true "Target data"
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the first response"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 201 {object} TargetView "Successfully added target to mission"
+// @Header 201 {string} ETag "Version of the target"
+// @Header 201 {string} Idempotent-Replayed "Set to true when the response is a replay"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 422 {object} Problem "Invalid fields, mission already has 3 targets, or Idempotency-Key reused for a different request"
+// @Failure 404 {object} Problem "Mission not found"
+// @Failure 409 {object} Problem "Mission is completed or aborted, or a request with the same Idempotency-Key is still being processed"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /missions/{id}/targets [post]
+// @Router /v2/missions/{id}/targets [post]
 func (h *MissionHandler) AddTargetToMissionHandler(c *gin.Context) {
-	var target models.Target
-	if err := c.ShouldBindJSON(&target); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	missionID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req CreateTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
 		return
 	}
 
-	missionID, _ := strconv.Atoi(c.Param("mission_id"))
-	if err := h.Service.AddTargetToMission(uint(missionID), &target); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	target := req.Target()
+	if err := h.Service.AddTargetToMission(c.Request.Context(), missionID, &target); err != nil {
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusCreated, target)
+	setETag(c, target.Version)
+	c.JSON(http.StatusCreated, newTargetView(&target))
 }
 
 // AssignCatToMissionHandler godoc
 // @Summary Assign a cat to a mission
-// @Description Assign a cat to a specific mission
-// @Param mission_id path int true "Mission ID"
+// @Description Assign a cat to a specific mission. Reassigning a mission records the cat it was taken from as previous_cat_id.
+// @Param id path int true "Mission ID"
 // @Param cat_id path int true "Cat ID"
-// @Success 200 {object} ErrorResponse "Successfully assigned cat to mission"
-// @Failure 400 {object} ErrorResponse "Invalid input"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /missions/{mission_id}/cats/{cat_id} [put]
+// @Param If-Match header string true "ETag of the mission"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Successfully assigned cat to mission"
+// @Header 200 {string} ETag "New version of the mission"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Mission or cat not found"
+// @Failure 409 {object} Problem "Cat already has an active mission"
+// @Failure 412 {object} Problem "Mission was changed since it was read"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /missions/{id}/cats/{cat_id} [put]
+// @Router /v2/missions/{id}/cats/{cat_id} [put]
 func (h *MissionHandler) AssignCatToMissionHandler(c *gin.Context) {
-	missionID, err := strconv.Atoi(c.Param("mission_id"))
+	missionID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	catID, err := pathID(c, "cat_id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err = h.Service.AssignCatToMission(c.Request.Context(), missionID, catID, version)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid mission_id"})
+		c.Error(err)
 		return
 	}
+	setETag(c, version)
 
-	catID, err := strconv.Atoi(c.Param("cat_id"))
+	c.JSON(http.StatusOK, MessageResponse{Message: "Cat assigned to mission successfully"})
+}
+
+// UnassignCatHandler godoc
+// @Summary Unassign the cat from a mission
+// @Description Take the cat off an active mission; the mission returns to the unassigned pool as a draft and remembers the cat as previous_cat_id
+// @Param id path int true "Mission ID"
+// @Param If-Match header string true "ETag of the mission"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MissionView "Unassigned mission"
+// @Header 200 {string} ETag "New version of the mission"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Mission not found or closed"
+// @Failure 409 {object} Problem "Mission has no cat assigned"
+// @Failure 412 {object} Problem "Mission was changed since it was read"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/missions/{id}/cats [delete]
+func (h *MissionHandler) UnassignCatHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid cat_id"})
+		c.Error(err)
 		return
 	}
 
-	if err := h.Service.AssignCatToMission(uint(missionID), uint(catID)); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Cat assigned to mission successfully"})
+	mission, err := h.Service.UnassignCat(c.Request.Context(), id, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, mission.Version)
+	c.JSON(http.StatusOK, newMissionView(mission))
 }
 
 // UpdateTargetStatusHandler godoc
 // @Summary Update target status
-// @Description Update the status of a specific target
-// @Param target body models.Target true "Target status data"
-// @Success 200 {object} ErrorResponse "Target status updated successfully"
-// @Failure 400 {object} ErrorResponse "Invalid ID format"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Description Update the status of a specific target. Completing the last open target completes its mission. Deprecated, use PUT /v2/missions/{id}/targets/{target_id}/status.
+// @Deprecated
+// @Param target body UpdateTargetStatusRequest true "Target ID and status"
+// @Param If-Match header string true "Version of the target being changed, as an ETag"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Target status updated successfully"
+// @Header 200 {string} ETag "New version of the target"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 422 {object} Problem "Invalid fields"
+// @Failure 404 {object} Problem "Target not found"
+// @Failure 409 {object} Problem "Target or mission is complete, or mission has no cat yet"
+// @Failure 412 {object} Problem "Target was changed since it was read"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /targets/status [put]
 func (h *MissionHandler) UpdateTargetStatusHandler(c *gin.Context) {
-	var target models.Target
-	if err := c.ShouldBindJSON(&target); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req UpdateTargetStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
 		return
 	}
 
-	if err := h.Service.UpdateTargetStatus(&target); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	target := models.Target{ID: int(req.ID), IsComplete: req.IsComplete, Version: version}
+	if err := h.Service.UpdateTargetStatus(c.Request.Context(), &target); err != nil {
+		c.Error(err)
 		return
 	}
+	setETag(c, target.Version)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Target status updated successfully"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "Target status updated successfully"})
 }
 
 // UpdateTargetNotesHandler godoc
 // @Summary Update target notes
-// @Description Update notes for a specific target
-// @Param target body models.Target true "Target notes data"
-// @Success 200 {object} ErrorResponse "Target notes updated successfully"
-// @Failure 400 {object} ErrorResponse "Invalid input"
-// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Description Update notes for a specific target. Deprecated, use PUT /v2/missions/{id}/targets/{target_id}/notes.
+// @Deprecated
+// @Param target body UpdateTargetNotesRequest true "Target ID and notes"
+// @Param If-Match header string true "Version of the target being changed, as an ETag"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Target notes updated successfully"
+// @Header 200 {string} ETag "New version of the target"
+// @Failure 400 {object} Problem "Malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 422 {object} Problem "Invalid fields"
+// @Failure 404 {object} Problem "Target not found"
+// @Failure 409 {object} Problem "Target or mission is complete"
+// @Failure 412 {object} Problem "Target was changed since it was read"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
 // @Router /targets/notes [put]
 func (h *MissionHandler) UpdateTargetNotesHandler(c *gin.Context) {
-	var target models.Target
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	if err := c.ShouldBindJSON(&target); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	var req UpdateTargetNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
 		return
 	}
 
-	if err := h.Service.UpdateTargetNotes(&target); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	target := models.Target{ID: int(req.ID), Notes: req.Notes, Version: version}
+	if err := h.Service.UpdateTargetNotes(c.Request.Context(), &target); err != nil {
+		c.Error(err)
 		return
 	}
+	setETag(c, target.Version)
 
-	c.JSON(http.StatusOK, gin.H{"message": "Target notes updated successfully"})
+	c.JSON(http.StatusOK, MessageResponse{Message: "Target notes updated successfully"})
 }
 
 // DeleteTargetHandler godoc
 // @Summary Delete a target
-// @Description Delete a target by its ID
-// @Param id path int true "Target ID"
-// @Success 204 {object} ErrorResponse "Successfully deleted target"
-// @Failure 400 {object} ErrorResponse "Invalid ID format"
-// @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /targets/{id} [delete]
+// @Description Delete a target by its ID. Deprecated, use DELETE /v2/missions/{id}/targets/{target_id}.
+// @Deprecated
+// @Param target_id path int true "Target ID"
+// @Param If-Match header string true "Version of the target being deleted, as an ETag"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 204 "Successfully deleted target"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Target not found"
+// @Failure 409 {object} Problem "Target or mission is complete"
+// @Failure 412 {object} Problem "Target was changed since it was read"
+// @Failure 422 {object} Problem "Target is the last one of its mission"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /targets/{target_id} [delete]
 func (h *MissionHandler) DeleteTargetHandler(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.Service.DeleteTarget(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	id, err := pathID(c, "target_id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.Service.DeleteTarget(c.Request.Context(), id, version); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateMissionTargetStatusHandler godoc
+// @Summary Update the status of a mission's target
+// @Description Complete a target of a mission. The first completed target starts the mission and the last one completes it. A completed target cannot be reopened, and the targets of a draft mission cannot be completed until a cat is assigned.
+// @Param id path int true "Mission ID"
+// @Param target_id path int true "Target ID"
+// @Param status body TargetStatusRequest true "Target status"
+// @Param If-Match header string true "Version of the target being changed, as an ETag"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Target status updated successfully"
+// @Header 200 {string} ETag "New version of the target"
+// @Failure 400 {object} Problem "Invalid ID or malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Mission or target not found"
+// @Failure 409 {object} Problem "Target or mission is complete, or mission has no cat yet"
+// @Failure 412 {object} Problem "Target was changed since it was read"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/missions/{id}/targets/{target_id}/status [put]
+func (h *MissionHandler) UpdateMissionTargetStatusHandler(c *gin.Context) {
+	missionID, targetID, ok := h.missionTarget(c)
+	if !ok {
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req TargetStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	target := models.Target{ID: int(targetID), MissionID: missionID, IsComplete: req.IsComplete, Version: version}
+	if err := h.Service.UpdateTargetStatus(c.Request.Context(), &target); err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, target.Version)
+	c.JSON(http.StatusOK, MessageResponse{Message: "Target status updated successfully"})
+}
+
+// UpdateMissionTargetNotesHandler godoc
+// @Summary Update the notes of a mission's target
+// @Description Replace the notes of a target of a mission
+// @Param id path int true "Mission ID"
+// @Param target_id path int true "Target ID"
+// @Param notes body TargetNotesRequest true "Target notes"
+// @Param If-Match header string true "Version of the target being changed, as an ETag"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MessageResponse "Target notes updated successfully"
+// @Header 200 {string} ETag "New version of the target"
+// @Failure 400 {object} Problem "Invalid ID or malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 422 {object} Problem "Notes too long"
+// @Failure 404 {object} Problem "Mission or target not found"
+// @Failure 409 {object} Problem "Target or mission is complete"
+// @Failure 412 {object} Problem "Target was changed since it was read"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/missions/{id}/targets/{target_id}/notes [put]
+func (h *MissionHandler) UpdateMissionTargetNotesHandler(c *gin.Context) {
+	missionID, targetID, ok := h.missionTarget(c)
+	if !ok {
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req TargetNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	target := models.Target{ID: int(targetID), MissionID: missionID, Notes: req.Notes, Version: version}
+	if err := h.Service.UpdateTargetNotes(c.Request.Context(), &target); err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, target.Version)
+	c.JSON(http.StatusOK, MessageResponse{Message: "Target notes updated successfully"})
+}
+
+// DeleteMissionTargetHandler godoc
+// @Summary Delete a mission's target
+// @Description Delete a target of a mission
+// @Param id path int true "Mission ID"
+// @Param target_id path int true "Target ID"
+// @Param If-Match header string true "Version of the target being deleted, as an ETag"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 204 "Successfully deleted target"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Mission or target not found"
+// @Failure 409 {object} Problem "Target or mission is complete"
+// @Failure 412 {object} Problem "Target was changed since it was read"
+// @Failure 422 {object} Problem "Target is the last one of its mission"
+// @Failure 428 {object} Problem "If-Match header missing"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/missions/{id}/targets/{target_id} [delete]
+func (h *MissionHandler) DeleteMissionTargetHandler(c *gin.Context) {
+	_, targetID, ok := h.missionTarget(c)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusNoContent, gin.H{"message": "Successfully deleted target"})
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.Service.DeleteTarget(c.Request.Context(), targetID, version); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreMissionHandler godoc
+// @Summary Restore a deleted mission
+// @Description Undo the soft delete of a mission (admins only)
+// @Param id path int true "Mission ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} MissionView "Restored mission"
+// @Header 200 {string} ETag "Version of the mission"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Not allowed to restore"
+// @Failure 404 {object} Problem "Mission not found"
+// @Failure 409 {object} Problem "Mission is not deleted or its cat has another active mission"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/missions/{id}/restore [post]
+func (h *MissionHandler) RestoreMissionHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	mission, err := h.Service.RestoreMission(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, mission.Version)
+	c.JSON(http.StatusOK, newMissionView(mission))
+}
+
+// MissionHistoryHandler godoc
+// @Summary Get mission history
+// @Description Get the audit trail of a mission and its targets: who changed which field, from what and to what, oldest first
+// @Param id path int true "Mission ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} HistoryResponse "Mission history"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Mission not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/missions/{id}/history [get]
+func (h *MissionHandler) MissionHistoryHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	events, err := h.Service.MissionHistory(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, HistoryResponse{Data: newAuditEventViews(events)})
+}
+
+// RestoreTargetHandler godoc
+// @Summary Restore a deleted target
+// @Description Undo the soft delete of a target of an open mission (admins only)
+// @Param id path int true "Target ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} TargetView "Restored target"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 403 {object} Problem "Not allowed to restore"
+// @Failure 404 {object} Problem "Target or mission not found"
+// @Failure 409 {object} Problem "Target is not deleted or mission is closed"
+// @Failure 422 {object} Problem "Mission already has 3 targets"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/targets/{id}/restore [post]
+func (h *MissionHandler) RestoreTargetHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	target, err := h.Service.RestoreTarget(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newTargetView(target))
+}
+
+// missionTarget parses the mission and target IDs of a nested target route
+// and checks that the target belongs to the mission.
+func (h *MissionHandler) missionTarget(c *gin.Context) (uint, uint, bool) {
+	missionID, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return 0, 0, false
+	}
+
+	targetID, err := pathID(c, "target_id")
+	if err != nil {
+		c.Error(err)
+		return 0, 0, false
+	}
+
+	if err := h.Service.TargetInMission(c.Request.Context(), missionID, targetID); err != nil {
+		c.Error(err)
+		return 0, 0, false
+	}
+	return missionID, targetID, true
+}
+
+// ListTargetNotesHandler godoc
+// @Summary Get target note revisions
+// @Description Get every revision of a target's notes, oldest first. The last revision is the target's current notes.
+// @Param id path int true "Target ID"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} TargetNoteListResponse "Note revisions"
+// @Failure 400 {object} Problem "Invalid ID format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Target not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/targets/{id}/notes [get]
+func (h *MissionHandler) ListTargetNotesHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	notes, err := h.Service.ListTargetNotes(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, TargetNoteListResponse{Data: newTargetNoteViews(notes)})
+}
+
+// AddTargetNoteHandler godoc
+// @Summary Add a target note revision
+// @Description Append a new revision to a target's notes, written by the given cat. Earlier revisions are kept.
+// @Param id path int true "Target ID"
+// @Param note body CreateTargetNoteRequest true "Author and note text"
+// @Param If-Match header string false "Version of the target, as an ETag"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 201 {object} TargetNoteView "Created revision"
+// @Header 201 {string} ETag "New version of the target"
+// @Failure 400 {object} Problem "Invalid ID format or malformed JSON"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Target not found"
+// @Failure 409 {object} Problem "Target or mission is complete"
+// @Failure 412 {object} Problem "Target was changed since it was read"
+// @Failure 422 {object} Problem "Invalid fields or unknown author"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/targets/{id}/notes [post]
+func (h *MissionHandler) AddTargetNoteHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req CreateTargetNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	note := req.TargetNote(id)
+	version, err = h.Service.AddTargetNote(c.Request.Context(), &note, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setETag(c, version)
+	c.JSON(http.StatusCreated, newTargetNoteView(&note))
+}
+
+// GetTargetNoteHandler godoc
+// @Summary Get a target note revision
+// @Description Get one revision of a target's notes by its number, starting at 1
+// @Param id path int true "Target ID"
+// @Param revision path int true "Revision number"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} TargetNoteView "Note revision"
+// @Failure 400 {object} Problem "Invalid ID or revision format"
+// @Failure 401 {object} Problem "Missing or invalid credentials"
+// @Failure 404 {object} Problem "Target or revision not found"
+// @Failure 500 {object} Problem "Internal server error"
+// @Router /v2/targets/{id}/notes/{revision} [get]
+func (h *MissionHandler) GetTargetNoteHandler(c *gin.Context) {
+	id, err := pathID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	revision, err := pathID(c, "revision")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	note, err := h.Service.GetTargetNote(c.Request.Context(), id, int(revision))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, newTargetNoteView(note))
+}
+
+type TargetNoteListResponse struct {
+	Data []TargetNoteView `json:"data"`
+}
+
+type MissionResponse struct {
+	Data MissionView `json:"data"`
+}
+
+type MissionListResponse struct {
+	Data   []MissionView `json:"data"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+type MissionTransitionRequest struct {
+	Status models.MissionStatus `json:"status" binding:"required"`
+}
+
+type CreateMissionRequest struct {
+	// CatID is optional; leave it out to create an unassigned draft mission.
+	CatID   uint                  `json:"cat_id"`
+	Targets []CreateTargetRequest `json:"targets" binding:"dive"`
+}
+
+func (r CreateMissionRequest) Mission() models.Mission {
+	mission := models.Mission{CatID: r.CatID}
+	for _, target := range r.Targets {
+		mission.Targets = append(mission.Targets, target.Target())
+	}
+	return mission
+}
+
+type UpdateMissionStatusRequest struct {
+	ID         uint `json:"id" binding:"required"`
+	IsComplete bool `json:"is_complete"`
+}
+
+// CreateTargetRequest describes a target; Country is an ISO 3166-1 alpha-2 code.
+type CreateTargetRequest struct {
+	Name    string `json:"name" binding:"required,max=255"`
+	Country string `json:"country" binding:"required,iso3166_1_alpha2"`
+	Notes   string `json:"notes" binding:"target_notes" maxLength:"2000"`
+}
+
+func (r CreateTargetRequest) Target() models.Target {
+	return models.Target{Name: r.Name, Country: r.Country, Notes: r.Notes}
+}
+
+type UpdateTargetStatusRequest struct {
+	ID         uint `json:"id" binding:"required"`
+	IsComplete bool `json:"is_complete"`
+}
+
+type UpdateTargetNotesRequest struct {
+	ID    uint   `json:"id" binding:"required"`
+	Notes string `json:"notes" binding:"target_notes" maxLength:"2000"`
+}
+
+type TargetStatusRequest struct {
+	IsComplete bool `json:"is_complete"`
+}
+
+type TargetNotesRequest struct {
+	Notes string `json:"notes" binding:"target_notes" maxLength:"2000"`
+}
+
+type CreateTargetNoteRequest struct {
+	AuthorCatID uint   `json:"author_cat_id" binding:"required"`
+	Body        string `json:"body" binding:"required,target_notes" maxLength:"2000"`
+}
+
+func (r CreateTargetNoteRequest) TargetNote(targetID uint) models.TargetNote {
+	return models.TargetNote{TargetID: targetID, AuthorCatID: r.AuthorCatID, Body: r.Body}
 }
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"bytes"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin/binding"
+	"io"
+	"sort"
+)
+
+// decodeCatPatch reads a JSON Merge Patch document for a cat. Unknown
+// members and nulls are rejected because no cat field can be removed.
+func decodeCatPatch(body io.Reader) (*models.CatPatch, error) {
+	var members map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&members); err != nil {
+		return nil, apperr.BadRequest("invalid_input", "patch must be a JSON object: %v", err)
+	}
+
+	var patch models.CatPatch
+	targets := map[string]interface{}{
+		"name":       &patch.Name,
+		"experience": &patch.Experience,
+		"breed":      &patch.Breed,
+		"salary":     &patch.Salary,
+	}
+
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []apperr.FieldError
+	for _, name := range names {
+		target, ok := targets[name]
+		switch {
+		case !ok:
+			fields = append(fields, apperr.FieldError{Field: name, Message: "is not an editable field"})
+		case bytes.Equal(bytes.TrimSpace(members[name]), []byte("null")):
+			fields = append(fields, apperr.FieldError{Field: name, Message: "cannot be removed"})
+		default:
+			if err := json.Unmarshal(members[name], target); err != nil {
+				fields = append(fields, apperr.FieldError{Field: name, Message: "has the wrong type"})
+			}
+		}
+	}
+	if len(fields) > 0 {
+		return nil, apperr.Validation("invalid_patch", "invalid patch").WithFields(fields...)
+	}
+	if err := binding.Validator.ValidateStruct(&patch); err != nil {
+		return nil, invalidInput(err)
+	}
+	return &patch, nil
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import "devTodTestTask/internal/apperr"
+
+// Problem is an RFC 7807 application/problem+json error body.
+type Problem struct {
+	// Type is a URI reference identifying the problem type, "about:blank" for plain HTTP errors.
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is the machine-readable error code, also the last segment of Type.
+	Code    string                 `json:"code,omitempty"`
+	Errors  []apperr.FieldError    `json:"errors,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// HistoryResponse lists audit events, oldest first.
+type HistoryResponse struct {
+	Data []AuditEventView `json:"data"`
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"devTodTestTask/internal/models"
+	"time"
+)
+
+// The view types below are the JSON representations of the models returned
+// by the API. Unset timestamps and references are rendered as null.
+
+type CatView struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Experience int        `json:"experience"`
+	Breed      string     `json:"breed"`
+	BreedID    *string    `json:"breed_id"`
+	Salary     float64    `json:"salary"`
+	Version    int        `json:"version"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+func newCatView(cat *models.Cat) CatView {
+	view := CatView{
+		ID:         cat.ID,
+		Name:       cat.Name,
+		Experience: cat.Experience,
+		Breed:      cat.Breed,
+		Salary:     cat.Salary,
+		Version:    cat.Version,
+		CreatedAt:  cat.CreatedAt,
+		UpdatedAt:  cat.UpdatedAt,
+		DeletedAt:  cat.DeletedAt,
+	}
+	if cat.BreedID != "" {
+		view.BreedID = &cat.BreedID
+	}
+	return view
+}
+
+func newCatViews(cats []models.Cat) []CatView {
+	views := make([]CatView, 0, len(cats))
+	for i := range cats {
+		views = append(views, newCatView(&cats[i]))
+	}
+	return views
+}
+
+type MissionView struct {
+	ID            uint                 `json:"id"`
+	CatID         *uint                `json:"cat_id"`
+	PreviousCatID *uint                `json:"previous_cat_id"`
+	Status        models.MissionStatus `json:"status"`
+	IsComplete    bool                 `json:"is_complete"`
+	Targets       []TargetView         `json:"targets"`
+	Version       int                  `json:"version"`
+	CompletedAt   *time.Time           `json:"completed_at"`
+	CreatedAt     *time.Time           `json:"created_at"`
+	UpdatedAt     *time.Time           `json:"updated_at"`
+	DeletedAt     *time.Time           `json:"deleted_at"`
+}
+
+func newMissionView(mission *models.Mission) MissionView {
+	view := MissionView{
+		ID:          mission.ID,
+		Status:      mission.Status,
+		IsComplete:  mission.IsComplete,
+		Targets:     newTargetViews(mission.Targets),
+		Version:     mission.Version,
+		CompletedAt: mission.CompletedAt,
+		CreatedAt:   mission.CreatedAt,
+		UpdatedAt:   mission.UpdatedAt,
+		DeletedAt:   mission.DeletedAt,
+	}
+	if mission.CatID != 0 {
+		view.CatID = &mission.CatID
+	}
+	if mission.PreviousCatID != 0 {
+		view.PreviousCatID = &mission.PreviousCatID
+	}
+	return view
+}
+
+func newMissionViews(missions []models.Mission) []MissionView {
+	views := make([]MissionView, 0, len(missions))
+	for i := range missions {
+		views = append(views, newMissionView(&missions[i]))
+	}
+	return views
+}
+
+type TargetView struct {
+	ID         int        `json:"id"`
+	MissionID  uint       `json:"mission_id"`
+	Name       string     `json:"name"`
+	Country    string     `json:"country"`
+	Notes      string     `json:"notes"`
+	IsComplete bool       `json:"is_complete"`
+	Version    int        `json:"version"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+func newTargetView(target *models.Target) TargetView {
+	return TargetView{
+		ID:         target.ID,
+		MissionID:  target.MissionID,
+		Name:       target.Name,
+		Country:    target.Country,
+		Notes:      target.Notes,
+		IsComplete: target.IsComplete,
+		Version:    target.Version,
+		CreatedAt:  target.CreatedAt,
+		UpdatedAt:  target.UpdatedAt,
+		DeletedAt:  target.DeletedAt,
+	}
+}
+
+func newTargetViews(targets []models.Target) []TargetView {
+	views := make([]TargetView, 0, len(targets))
+	for i := range targets {
+		views = append(views, newTargetView(&targets[i]))
+	}
+	return views
+}
+
+type TargetNoteView struct {
+	Revision    int        `json:"revision"`
+	TargetID    uint       `json:"target_id"`
+	AuthorCatID *uint      `json:"author_cat_id"`
+	Body        string     `json:"body"`
+	CreatedAt   *time.Time `json:"created_at"`
+}
+
+func newTargetNoteView(note *models.TargetNote) TargetNoteView {
+	view := TargetNoteView{
+		Revision:  note.Revision,
+		TargetID:  note.TargetID,
+		Body:      note.Body,
+		CreatedAt: note.CreatedAt,
+	}
+	if note.AuthorCatID != 0 {
+		view.AuthorCatID = &note.AuthorCatID
+	}
+	return view
+}
+
+func newTargetNoteViews(notes []models.TargetNote) []TargetNoteView {
+	views := make([]TargetNoteView, 0, len(notes))
+	for i := range notes {
+		views = append(views, newTargetNoteView(&notes[i]))
+	}
+	return views
+}
+
+type AuditEventView struct {
+	ID         int64              `json:"id"`
+	Actor      string             `json:"actor"`
+	EntityType models.AuditEntity `json:"entity_type"`
+	EntityID   uint               `json:"entity_id"`
+	MissionID  *uint              `json:"mission_id"`
+	Field      string             `json:"field"`
+	OldValue   *string            `json:"old_value"`
+	NewValue   *string            `json:"new_value"`
+	CreatedAt  *time.Time         `json:"created_at"`
+}
+
+func newAuditEventViews(events []models.AuditEvent) []AuditEventView {
+	views := make([]AuditEventView, 0, len(events))
+	for _, event := range events {
+		view := AuditEventView{
+			ID:         event.ID,
+			Actor:      event.Actor,
+			EntityType: event.EntityType,
+			EntityID:   event.EntityID,
+			Field:      event.Field,
+			OldValue:   event.OldValue,
+			NewValue:   event.NewValue,
+			CreatedAt:  event.CreatedAt,
+		}
+		if event.MissionID != 0 {
+			missionID := event.MissionID
+			view.MissionID = &missionID
+		}
+		views = append(views, view)
+	}
+	return views
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/auth"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// Authenticator resolves the credentials of a request to a principal.
+type Authenticator interface {
+	APIKeyPrincipal(ctx context.Context, key string) (auth.Principal, error)
+	TokenPrincipal(ctx context.Context, token string) (auth.Principal, error)
+}
+
+// Authenticate rejects requests without valid credentials with 401 and puts
+// the caller's principal in the request context. Credentials are an API key in
+// X-API-Key, or an API key or JWT in "Authorization: Bearer".
+func Authenticate(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var principal auth.Principal
+		var err error
+		key, token := credentials(c)
+		switch {
+		case key != "":
+			principal, err = authenticator.APIKeyPrincipal(ctx, key)
+		case token != "":
+			principal, err = authenticator.TokenPrincipal(ctx, token)
+		default:
+			err = apperr.Unauthorized("authentication_required", "an API key or bearer token is required")
+		}
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="spy-cats"`)
+			abort(c, err)
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(ctx, principal))
+		c.Next()
+	}
+}
+
+// credentials returns the API key or the JWT the request carries. A bearer
+// credential is taken for a JWT when it has the three dot-separated parts of one.
+func credentials(c *gin.Context) (key, token string) {
+	if key = c.GetHeader("X-API-Key"); key != "" {
+		return key, ""
+	}
+
+	scheme, credential, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", ""
+	}
+	credential = strings.TrimSpace(credential)
+	if strings.Count(credential, ".") == 2 {
+		return "", credential
+	}
+	return credential, ""
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// Deprecated marks a route as deprecated (RFC 9745) and, when the successor
+// path can be filled from the route's own parameters, links to it. Path
+// parameters in successor are written gin-style, e.g. "/v2/cats/:id".
+func Deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		if link, ok := expandPath(successor, c.Params); ok {
+			c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", link))
+		}
+		c.Next()
+	}
+}
+
+func expandPath(path string, params gin.Params) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		value, ok := params.Get(segment[1:])
+		if !ok {
+			return "", false
+		}
+		segments[i] = value
+	}
+	return strings.Join(segments, "/"), true
+}
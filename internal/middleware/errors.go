@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/handlers"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const problemContentType = "application/problem+json"
+
+var kindStatus = map[apperr.Kind]int{
+	apperr.KindBadRequest:           http.StatusBadRequest,
+	apperr.KindUnauthorized:         http.StatusUnauthorized,
+	apperr.KindNotFound:             http.StatusNotFound,
+	apperr.KindConflict:             http.StatusConflict,
+	apperr.KindValidation:           http.StatusUnprocessableEntity,
+	apperr.KindForbidden:            http.StatusForbidden,
+	apperr.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperr.KindPreconditionRequired: http.StatusPreconditionRequired,
+}
+
+// Errors renders the last error a handler attached with c.Error as an
+// RFC 7807 problem. Typed errors keep their message and code; anything else
+// becomes a 500 whose details only go to the log.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		if _, ok := apperr.As(err); !ok && errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+			// The driver may report a query cancelled by the deadline with an error of its own
+			err = context.DeadlineExceeded
+		}
+		writeProblem(c, problemFor(err))
+	}
+}
+
+func problemFor(err error) handlers.Problem {
+	if appErr, ok := apperr.As(err); ok {
+		status, ok := kindStatus[appErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		return handlers.Problem{
+			Type:    "/problems/" + appErr.Code,
+			Title:   http.StatusText(status),
+			Status:  status,
+			Detail:  appErr.Message,
+			Code:    appErr.Code,
+			Errors:  appErr.Fields,
+			Details: appErr.Details,
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return handlers.Problem{
+			Type:   "/problems/timeout",
+			Title:  http.StatusText(http.StatusGatewayTimeout),
+			Status: http.StatusGatewayTimeout,
+			Detail: "request timed out",
+			Code:   "timeout",
+		}
+	}
+	return handlers.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+	}
+}
+
+func writeProblem(c *gin.Context, problem handlers.Problem) {
+	problem.Instance = c.Request.URL.Path
+	c.Header("Content-Type", problemContentType)
+	c.JSON(problem.Status, problem)
+}
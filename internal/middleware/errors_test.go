@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"devTodTestTask/internal/repo"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorsStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		status  int
+	}{
+		{"typed error", func(c *gin.Context) { c.Error(repo.ErrCatNotFound) }, http.StatusNotFound},
+		{"wrapped typed error", func(c *gin.Context) {
+			c.Error(fmt.Errorf("could not delete cat: %w", repo.ErrCatHasActiveMission))
+		}, http.StatusConflict},
+		{"wrapped deadline", func(c *gin.Context) {
+			c.Error(fmt.Errorf("could not get cat: %w", context.DeadlineExceeded))
+		}, http.StatusGatewayTimeout},
+		{"driver error after deadline", func(c *gin.Context) {
+			<-c.Request.Context().Done()
+			c.Error(errors.New("pq: canceling statement due to user request"))
+		}, http.StatusGatewayTimeout},
+		{"plain error", func(c *gin.Context) { c.Error(errors.New("boom")) }, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(Errors(), Timeout(10*time.Millisecond))
+			r.GET("/", tt.handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.status, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); got != problemContentType {
+				t.Errorf("Content-Type = %q, want %q", got, problemContentType)
+			}
+		})
+	}
+}
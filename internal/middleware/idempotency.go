@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/models"
+	"devTodTestTask/internal/repo"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"time"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers stored with an idempotent response.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Idempotency makes retries of a request carrying an Idempotency-Key header
+// safe: the first successful response is stored and replayed to every retry
+// within window. Reusing a key for a different request is rejected with 422,
+// and a retry arriving while the first request is still running with 409.
+// Failed or panicking requests do not keep their key, so they can be retried
+// as they are. A key held for longer than lease without a response belonged
+// to a request that died, and the next retry takes it over.
+func Idempotency(store repo.IdempotencyStore, window, lease time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abort(c, apperr.BadRequest("invalid_idempotency_key", "Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+			return
+		}
+
+		hash, err := requestHash(c)
+		if err != nil {
+			abort(c, apperr.BadRequest("unreadable_body", "could not read request body: %v", err))
+			return
+		}
+
+		ctx := c.Request.Context()
+		record := &models.IdempotencyRecord{Subject: auth.FromContext(ctx).Subject, Key: key, RequestHash: hash}
+		now := time.Now()
+		existing, err := store.ReserveIdempotencyKey(ctx, record, now.Add(-window), now.Add(-lease))
+		if err != nil {
+			abort(c, err)
+			return
+		}
+		if existing != nil {
+			replay(c, existing, hash)
+			return
+		}
+
+		// The outcome is kept even when the request's deadline has passed meanwhile.
+		release := func() {
+			if err := store.ReleaseIdempotencyKey(context.WithoutCancel(ctx), record); err != nil {
+				fmt.Printf("Releasing idempotency key failed. Error: %v\n", err)
+			}
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				release()
+				panic(p)
+			}
+		}()
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		ctx = context.WithoutCancel(ctx)
+
+		// Errors are rendered after this middleware returns, so only successes are stored.
+		status := writer.Status()
+		if len(c.Errors) > 0 || status < 200 || status >= 300 {
+			release()
+			return
+		}
+
+		record.StatusCode = status
+		record.Body = writer.body.Bytes()
+		record.Header = make(map[string]string)
+		for _, name := range replayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				record.Header[name] = value
+			}
+		}
+		if err = store.SaveIdempotentResponse(ctx, record); err != nil {
+			fmt.Printf("Saving idempotent response failed. Error: %v\n", err)
+		}
+	}
+}
+
+// replay answers a request whose key is already held.
+func replay(c *gin.Context, existing *models.IdempotencyRecord, hash string) {
+	switch {
+	case existing.RequestHash != hash:
+		abort(c, apperr.Validation("idempotency_key_reused", "Idempotency-Key was already used for a different request"))
+	case existing.StatusCode == 0:
+		abort(c, apperr.Conflict("idempotency_key_in_use", "a request with this Idempotency-Key is still being processed"))
+	default:
+		for name, value := range existing.Header {
+			c.Header(name, value)
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Writer.WriteHeader(existing.StatusCode)
+		c.Writer.Write(existing.Body)
+		c.Abort()
+	}
+}
+
+// requestHash identifies a request by its method, path and body. The body is
+// put back for the handler.
+func requestHash(c *gin.Context) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", c.Request.Method, c.Request.URL.Path)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// recordingWriter keeps a copy of the response body written through it.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
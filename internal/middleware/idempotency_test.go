@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"devTodTestTask/internal/repo"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotentServer serves POST /cats through the Idempotency middleware,
+// calling handler with the number of the call, starting at 1.
+func idempotentServer(handler func(c *gin.Context, call int)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	store := repo.NewMemoryStore()
+	calls := 0
+
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}), Errors())
+	r.POST("/cats", Idempotency(store, time.Hour, time.Minute), func(c *gin.Context) {
+		calls++
+		handler(c, calls)
+	})
+	return r
+}
+
+func post(r http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/cats", strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func created(c *gin.Context, call int) {
+	c.Header("ETag", `"1"`)
+	c.JSON(http.StatusCreated, gin.H{"call": call})
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	r := idempotentServer(created)
+
+	first := post(r, "k1", `{"name":"Tom"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want 201", first.Code)
+	}
+	retry := post(r, "k1", `{"name":"Tom"}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Fatalf("retry = %d %s, want the first response %s", retry.Code, retry.Body, first.Body)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Header().Get("ETag") != `"1"` {
+		t.Errorf("retry headers = %v, want the replay marker and the stored ETag", retry.Header())
+	}
+
+	if other := post(r, "k2", `{"name":"Tom"}`); !strings.Contains(other.Body.String(), `"call":2`) {
+		t.Errorf("another key got %s, want a new call", other.Body)
+	}
+}
+
+func TestIdempotencyRejectsDifferentRequest(t *testing.T) {
+	r := idempotentServer(created)
+
+	post(r, "k1", `{"name":"Tom"}`)
+	w := post(r, "k1", `{"name":"Felix"}`)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "idempotency_key_reused") {
+		t.Errorf("reused key = %d %s, want 422 idempotency_key_reused", w.Code, w.Body)
+	}
+}
+
+func TestIdempotencyRejectsRetryInFlight(t *testing.T) {
+	var inFlight *httptest.ResponseRecorder
+	var r *gin.Engine
+	r = idempotentServer(func(c *gin.Context, call int) {
+		if call == 1 {
+			// The retry arrives while the first request is still being handled
+			inFlight = post(r, "k1", `{"name":"Tom"}`)
+		}
+		created(c, call)
+	})
+
+	if w := post(r, "k1", `{"name":"Tom"}`); w.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want 201", w.Code)
+	}
+	if inFlight.Code != http.StatusConflict || !strings.Contains(inFlight.Body.String(), "idempotency_key_in_use") {
+		t.Errorf("retry in flight = %d %s, want 409 idempotency_key_in_use", inFlight.Code, inFlight.Body)
+	}
+}
+
+func TestIdempotencyReleasesFailedRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func(c *gin.Context)
+	}{
+		{"error", func(c *gin.Context) { c.Error(errors.New("boom")) }},
+		{"non-2xx response", func(c *gin.Context) { c.JSON(http.StatusServiceUnavailable, gin.H{}) }},
+		{"panic", func(c *gin.Context) { panic("boom") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := idempotentServer(func(c *gin.Context, call int) {
+				if call == 1 {
+					tt.fail(c)
+					return
+				}
+				created(c, call)
+			})
+
+			if w := post(r, "k1", `{"name":"Tom"}`); w.Code < 300 {
+				t.Fatalf("failed request status = %d, want an error", w.Code)
+			}
+			w := post(r, "k1", `{"name":"Tom"}`)
+			if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+				t.Errorf("retry = %d %s, want the handler to run again", w.Code, w.Body)
+			}
+		})
+	}
+}
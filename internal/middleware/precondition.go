@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"devTodTestTask/internal/apperr"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RequireIfMatch rejects PUT, PATCH and DELETE requests without an If-Match
+// header with 428 Precondition Required, so that clients cannot overwrite a
+// change they have not seen. Checking the tag itself is left to the handlers.
+func RequireIfMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			if c.GetHeader("If-Match") == "" {
+				c.Error(apperr.PreconditionRequired("if_match_required", "%s requires an If-Match header with the resource's ETag", c.Request.Method))
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
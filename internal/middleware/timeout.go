@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// Timeout attaches a deadline to the request context so that queries of slow
+// or abandoned requests are cancelled.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
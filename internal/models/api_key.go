@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// APIKey is a credential issued to a client. Only the SHA-256 of the key is
+// stored; the key itself is shown once when it is issued.
+type APIKey struct {
+	ID uint `json:"id"`
+	// Name identifies the client and is recorded as the actor of its changes.
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	KeyHash    string     `json:"-"`
+	CreatedAt  *time.Time `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
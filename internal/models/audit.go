@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type AuditEntity string
+
+const (
+	AuditCat     AuditEntity = "cat"
+	AuditMission AuditEntity = "mission"
+	AuditTarget  AuditEntity = "target"
+)
+
+// AuditEvent records one change of a field of a cat, mission or target.
+// Values are kept as text and are nil where the field was NULL.
+type AuditEvent struct {
+	ID         int64       `json:"id"`
+	Actor      string      `json:"actor"`
+	EntityType AuditEntity `json:"entity_type"`
+	EntityID   uint        `json:"entity_id"`
+	// MissionID is the mission a mission or target event belongs to, 0 for cat events.
+	MissionID uint       `json:"mission_id,omitempty"`
+	Field     string     `json:"field"`
+	OldValue  *string    `json:"old_value"`
+	NewValue  *string    `json:"new_value"`
+	CreatedAt *time.Time `json:"created_at"`
+}
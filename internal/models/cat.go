@@ -2,13 +2,65 @@ package models
 
 import "time"
 
+// Timestamps are nil while the corresponding column is NULL. Version is
+// incremented on every change of a cat, mission or target row and backs its ETag.
 type Cat struct {
-	ID         int       `json:"id"`
-	Name       string    `json:"name,omitempty"`
-	Experience int       `json:"experience,omitempty"`
-	Breed      string    `json:"breed,omitempty"`
-	Salary     float64   `json:"salary,omitempty"`
-	CreatedAt  time.Time `json:"createdAt,omitempty"`
-	UpdatedAt  time.Time `json:"updatedAt,omitempty"`
-	DeletedAt  time.Time `json:"deletedAt,omitempty"`
+	ID         int        `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	Experience int        `json:"experience,omitempty"`
+	Breed      string     `json:"breed,omitempty"`
+	BreedID    string     `json:"breed_id,omitempty"`
+	Salary     float64    `json:"salary,omitempty"`
+	Version    int        `json:"version,omitempty"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+type CatFilter struct {
+	Page
+	Breed         string   `form:"breed"`
+	MinExperience *int     `form:"min_experience" binding:"omitempty,min=0"`
+	MaxExperience *int     `form:"max_experience" binding:"omitempty,min=0"`
+	MinSalary     *float64 `form:"min_salary" binding:"omitempty,min=0"`
+	MaxSalary     *float64 `form:"max_salary" binding:"omitempty,min=0"`
+	SortBy        string   `form:"sort_by" binding:"omitempty,oneof=id name experience breed salary created_at updated_at"`
+	Order         string   `form:"order" binding:"omitempty,oneof=asc desc"`
+	// IncludeDeleted also lists soft-deleted cats; it is reserved for admins.
+	IncludeDeleted bool `form:"include_deleted"`
+}
+
+// CatRetirement says what happens to the active mission of a cat being deleted.
+// With neither option set, a cat on an active mission cannot be deleted.
+type CatRetirement struct {
+	// ReassignTo hands the mission over to another cat.
+	ReassignTo uint `form:"reassign_to"`
+	// Unassign returns the mission to the unassigned pool as a draft.
+	Unassign bool `form:"unassign"`
+}
+
+// CatPatch holds the fields of a JSON Merge Patch on a cat; nil fields are left unchanged.
+type CatPatch struct {
+	Name       *string  `json:"name,omitempty" binding:"omitnil,min=1,max=100"`
+	Experience *int     `json:"experience,omitempty" binding:"omitnil,min=0"`
+	Breed      *string  `json:"breed,omitempty" binding:"omitnil,min=1,max=100"`
+	Salary     *float64 `json:"salary,omitempty" binding:"omitnil,min=0"`
+}
+
+// Fields returns the JSON names of the fields set in the patch.
+func (p CatPatch) Fields() []string {
+	var fields []string
+	if p.Name != nil {
+		fields = append(fields, "name")
+	}
+	if p.Experience != nil {
+		fields = append(fields, "experience")
+	}
+	if p.Breed != nil {
+		fields = append(fields, "breed")
+	}
+	if p.Salary != nil {
+		fields = append(fields, "salary")
+	}
+	return fields
 }
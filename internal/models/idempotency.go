@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyRecord is the response given to the first request that carried
+// an Idempotency-Key, replayed to retries of the same request.
+type IdempotencyRecord struct {
+	// Subject is the principal that sent the key; keys of different callers never collide.
+	Subject string
+	Key     string
+	// RequestHash identifies the method, path and body the key was first used with.
+	RequestHash string
+	// StatusCode is 0 while the first request is still being handled.
+	StatusCode int
+	Header     map[string]string
+	Body       []byte
+	CreatedAt  *time.Time
+}
@@ -2,12 +2,78 @@ package models
 
 import "time"
 
+type MissionStatus string
+
+const (
+	MissionDraft      MissionStatus = "draft"
+	MissionAssigned   MissionStatus = "assigned"
+	MissionInProgress MissionStatus = "in_progress"
+	MissionCompleted  MissionStatus = "completed"
+	MissionAborted    MissionStatus = "aborted"
+)
+
+// Valid reports whether s is one of the known mission statuses.
+func (s MissionStatus) Valid() bool {
+	switch s {
+	case MissionDraft, MissionAssigned, MissionInProgress, MissionCompleted, MissionAborted:
+		return true
+	}
+	return false
+}
+
+// Closed reports whether a mission in this status can no longer be worked on.
+func (s MissionStatus) Closed() bool {
+	return s == MissionCompleted || s == MissionAborted
+}
+
+// missionTransitions lists the statuses a mission may move to from each status.
+var missionTransitions = map[MissionStatus][]MissionStatus{
+	MissionDraft:      {MissionAssigned, MissionAborted},
+	MissionAssigned:   {MissionInProgress, MissionCompleted, MissionAborted},
+	MissionInProgress: {MissionCompleted, MissionAborted},
+	MissionCompleted:  {},
+	MissionAborted:    {},
+}
+
+// Next returns the statuses a mission may move to from s.
+func (s MissionStatus) Next() []MissionStatus {
+	return missionTransitions[s]
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving from s to next.
+func (s MissionStatus) CanTransitionTo(next MissionStatus) bool {
+	for _, allowed := range missionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type Mission struct {
-	ID         uint      `json:"id"`
-	CatID      uint      `json:"cat_id,omitempty"`
-	IsComplete bool      `json:"is_complete,omitempty"`
-	Targets    []Target  `json:"targets,omitempty"`
-	CreatedAt  time.Time `json:"createdAt,omitempty"`
-	UpdatedAt  time.Time `json:"updatedAt,omitempty"`
-	DeletedAt  time.Time `json:"deletedAt,omitempty"`
+	ID uint `json:"id"`
+	// CatID is 0 while the mission is unassigned (cat_id IS NULL).
+	CatID uint `json:"cat_id,omitempty"`
+	// PreviousCatID is the cat the mission was last taken from, 0 if none.
+	PreviousCatID uint          `json:"previous_cat_id,omitempty"`
+	Status        MissionStatus `json:"status,omitempty"`
+	IsComplete    bool          `json:"is_complete,omitempty"`
+	Targets       []Target      `json:"targets,omitempty"`
+	Version       int           `json:"version,omitempty"`
+	CompletedAt   *time.Time    `json:"completed_at"`
+	CreatedAt     *time.Time    `json:"created_at"`
+	UpdatedAt     *time.Time    `json:"updated_at"`
+	DeletedAt     *time.Time    `json:"deleted_at"`
+}
+
+type MissionFilter struct {
+	Page
+	Status      string     `form:"status" binding:"omitempty,oneof=draft assigned in_progress completed aborted"`
+	IsComplete  *bool      `form:"is_complete"`
+	CatID       *uint      `form:"cat_id" binding:"omitempty,min=1"`
+	CreatedFrom *time.Time `form:"created_from"`
+	CreatedTo   *time.Time `form:"created_to"`
+	Unassigned  *bool      `form:"unassigned"`
+	// IncludeDeleted also lists soft-deleted missions and targets; it is reserved for admins.
+	IncludeDeleted bool `form:"include_deleted"`
 }
@@ -0,0 +1,24 @@
+package models
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+type Page struct {
+	Limit  int `form:"limit" binding:"omitempty,min=0"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Normalize clamps the page into the allowed limit range.
+func (p *Page) Normalize() {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+}
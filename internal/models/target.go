@@ -2,14 +2,37 @@ package models
 
 import "time"
 
+// A mission always has between MinTargetsPerMission and MaxTargetsPerMission non-deleted targets:
+// it is created with that many, and adding, deleting or restoring a target keeps it in range.
+const (
+	MinTargetsPerMission = 1
+	MaxTargetsPerMission = 3
+)
+
+// MaxTargetNotesLength is the longest notes text accepted for a target, in characters.
+const MaxTargetNotesLength = 2000
+
 type Target struct {
-	ID         int       `json:"id"`
-	MissionID  uint      `json:"mission_id,omitempty"`
-	Name       string    `json:"name,omitempty" `
-	Country    string    `json:"country,omitempty" `
-	Notes      string    `json:"notes,omitempty"`
-	IsComplete bool      `json:"is_complete,omitempty"`
-	CreatedAt  time.Time `json:"createdAt,omitempty"`
-	UpdatedAt  time.Time `json:"updatedAt,omitempty"`
-	DeletedAt  time.Time `json:"deletedAt,omitempty"`
+	ID         int        `json:"id"`
+	MissionID  uint       `json:"mission_id,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Country    string     `json:"country,omitempty"`
+	Notes      string     `json:"notes,omitempty"`
+	IsComplete bool       `json:"is_complete,omitempty"`
+	Version    int        `json:"version,omitempty"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+// TargetNote is one revision of a target's notes. Revisions are numbered from 1
+// per target and never change; the latest one is the target's current notes.
+type TargetNote struct {
+	ID       uint `json:"id"`
+	TargetID uint `json:"target_id"`
+	Revision int  `json:"revision"`
+	// AuthorCatID is the cat that wrote the revision, 0 if unknown.
+	AuthorCatID uint       `json:"author_cat_id,omitempty"`
+	Body        string     `json:"body"`
+	CreatedAt   *time.Time `json:"created_at"`
 }
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"devTodTestTask/internal/models"
+	"fmt"
+	"time"
+)
+
+type APIKeyRepository struct {
+	DB *sql.DB
+}
+
+func (repo *APIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	query := `INSERT INTO api_keys (name, role, key_hash, created_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	err := repo.DB.QueryRowContext(ctx, query, key.Name, key.Role, key.KeyHash, time.Now()).Scan(&key.ID, &key.CreatedAt)
+	if isUniqueViolation(err, "api_keys_active_name_idx") {
+		return ErrAPIKeyNameTaken
+	}
+	if err != nil {
+		return fmt.Errorf("could not create API key: %w", err)
+	}
+	return nil
+}
+
+// UseAPIKey returns the unrevoked key with the given hash and records that it was used.
+func (repo *APIKeyRepository) UseAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `UPDATE api_keys SET last_used_at = $1
+			  WHERE key_hash = $2 AND revoked_at IS NULL
+			  RETURNING id, name, role, key_hash, created_at, last_used_at, revoked_at`
+	err := repo.DB.QueryRowContext(ctx, query, time.Now(), keyHash).
+		Scan(&key.ID, &key.Name, &key.Role, &key.KeyHash, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, notFoundOr(err, ErrAPIKeyNotFound, "could not get API key")
+	}
+	return &key, nil
+}
+
+// ListAPIKeys returns every key, revoked ones included, oldest first.
+func (repo *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	query := `SELECT id, name, role, key_hash, created_at, last_used_at, revoked_at FROM api_keys ORDER BY id`
+	rows, err := repo.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("could not list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err = rows.Scan(&key.ID, &key.Name, &key.Role, &key.KeyHash, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt); err != nil {
+			return nil, fmt.Errorf("could not scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (repo *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uint) error {
+	var revokedID uint
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL RETURNING id`
+	if err := repo.DB.QueryRowContext(ctx, query, time.Now(), id).Scan(&revokedID); err != nil {
+		return notFoundOr(err, ErrAPIKeyNotFound, "could not revoke API key")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/models"
+	"fmt"
+	"time"
+)
+
+// auditChanges collects the field changes made by one mutation so they can be
+// recorded together with it. Fields whose value did not change are skipped.
+type auditChanges []models.AuditEvent
+
+func (c *auditChanges) add(entity models.AuditEntity, entityID, missionID uint, field string, oldValue, newValue interface{}) {
+	before, after := auditValue(oldValue), auditValue(newValue)
+	if sameValue(before, after) {
+		return
+	}
+	*c = append(*c, models.AuditEvent{
+		EntityType: entity,
+		EntityID:   entityID,
+		MissionID:  missionID,
+		Field:      field,
+		OldValue:   before,
+		NewValue:   after,
+	})
+}
+
+// missionCat records a change of the cat a mission is assigned to.
+func (c *auditChanges) missionCat(missionID, oldCatID, newCatID uint) {
+	c.add(models.AuditMission, missionID, missionID, "cat_id", catRef(oldCatID), catRef(newCatID))
+}
+
+// catRef returns nil for the zero cat ID of an unassigned mission.
+func catRef(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func auditValue(v interface{}) *string {
+	if v == nil {
+		return nil
+	}
+	s := fmt.Sprint(v)
+	return &s
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// stamp attributes the changes to the principal in ctx at the given time.
+func (c auditChanges) stamp(ctx context.Context, at time.Time) {
+	actor := auth.FromContext(ctx).Subject
+	for i := range c {
+		c[i].Actor = actor
+		c[i].CreatedAt = &at
+	}
+}
+
+// record inserts the changes in the transaction of the mutation that made them.
+func (c auditChanges) record(ctx context.Context, tx *sql.Tx) error {
+	c.stamp(ctx, time.Now())
+	query := `INSERT INTO audit_events (actor, entity_type, entity_id, mission_id, field, old_value, new_value, created_at)
+			  VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7, $8)`
+	for _, event := range c {
+		_, err := tx.ExecContext(ctx, query, event.Actor, event.EntityType, event.EntityID, event.MissionID,
+			event.Field, event.OldValue, event.NewValue, event.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("could not record audit event: %w", err)
+		}
+	}
+	return nil
+}
+
+type AuditRepository struct {
+	DB *sql.DB
+}
+
+// MissionHistory returns the events of a mission and its targets, oldest first.
+func (repo *AuditRepository) MissionHistory(ctx context.Context, missionID uint) ([]models.AuditEvent, error) {
+	query := `SELECT id, actor, entity_type, entity_id, COALESCE(mission_id, 0), field, old_value, new_value, created_at
+			  FROM audit_events
+			  WHERE mission_id = $1
+			  ORDER BY id`
+	return repo.history(ctx, query, missionID)
+}
+
+// CatHistory returns the events of a cat together with the assignments of
+// missions to and from it, oldest first.
+func (repo *AuditRepository) CatHistory(ctx context.Context, catID uint) ([]models.AuditEvent, error) {
+	query := `SELECT id, actor, entity_type, entity_id, COALESCE(mission_id, 0), field, old_value, new_value, created_at
+			  FROM audit_events
+			  WHERE (entity_type = 'cat' AND entity_id = $1)
+			     OR (entity_type = 'mission' AND field = 'cat_id' AND (old_value = $1::text OR new_value = $1::text))
+			  ORDER BY id`
+	return repo.history(ctx, query, catID)
+}
+
+func (repo *AuditRepository) history(ctx context.Context, query string, id uint) ([]models.AuditEvent, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get history: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var event models.AuditEvent
+		err = rows.Scan(&event.ID, &event.Actor, &event.EntityType, &event.EntityID, &event.MissionID,
+			&event.Field, &event.OldValue, &event.NewValue, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan audit event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get history: %w", err)
+	}
+	return events, nil
+}
+
+// catChanges lists the fields changed between two states of a cat.
+func catChanges(old, updated *models.Cat) auditChanges {
+	var changes auditChanges
+	id := uint(updated.ID)
+	changes.add(models.AuditCat, id, 0, "name", old.Name, updated.Name)
+	changes.add(models.AuditCat, id, 0, "experience", old.Experience, updated.Experience)
+	changes.add(models.AuditCat, id, 0, "breed", old.Breed, updated.Breed)
+	changes.add(models.AuditCat, id, 0, "salary", old.Salary, updated.Salary)
+	return changes
+}
@@ -1,9 +1,12 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"devTodTestTask/internal/models"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,82 +14,279 @@ type CatRepository struct {
 	DB *sql.DB
 }
 
-func (repo *CatRepository) CreateCat(cat *models.Cat) error {
+func (repo *CatRepository) CreateCat(ctx context.Context, cat *models.Cat) error {
 	query := `INSERT INTO 
-				    cats (name, experience, breed, salary,created_at)
+				    cats (name, experience, breed, breed_id, salary, created_at)
               VALUES 
-                  ($1, $2, $3, $4, $5) 
-              RETURNING id,created_at`
+                  ($1, $2, $3, $4, $5, $6) 
+              RETURNING id, created_at, version`
 
-	return repo.DB.QueryRow(query, cat.Name, cat.Experience, cat.Breed, cat.Salary, time.Now()).Scan(&cat.ID, &cat.CreatedAt)
+	return repo.DB.QueryRowContext(ctx, query, cat.Name, cat.Experience, cat.Breed, cat.BreedID, cat.Salary, time.Now()).Scan(&cat.ID, &cat.CreatedAt, &cat.Version)
 }
 
-func (repo *CatRepository) ListCats() ([]models.Cat, error) {
-	var cats []models.Cat
-	rows, err := repo.DB.Query(`SELECT 
-    									id, name, experience, breed, salary, created_at, updated_at 
-									FROM 
-									    public.cats 
-									WHERE 
-									    deleted_at IS NULL`)
+// catSortColumns maps the sort_by values accepted by the API to cats columns.
+var catSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"experience": "experience",
+	"breed":      "breed",
+	"salary":     "salary",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func (repo *CatRepository) ListCats(ctx context.Context, filter models.CatFilter) ([]models.Cat, int, error) {
+	var conditions []string
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Breed != "" {
+		addCondition("LOWER(breed) = LOWER($%d)", strings.TrimSpace(filter.Breed))
+	}
+	if filter.MinExperience != nil {
+		addCondition("experience >= $%d", *filter.MinExperience)
+	}
+	if filter.MaxExperience != nil {
+		addCondition("experience <= $%d", *filter.MaxExperience)
+	}
+	if filter.MinSalary != nil {
+		addCondition("salary >= $%d", *filter.MinSalary)
+	}
+	if filter.MaxSalary != nil {
+		addCondition("salary <= $%d", *filter.MaxSalary)
+	}
+	where := "TRUE"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	// Count all matching cats so clients can page through the result
+	var total int
+	err := repo.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM cats WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not count cats: %w", err)
+	}
+
+	sortColumn, ok := catSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = "id"
+	}
+	order := "ASC"
+	if strings.EqualFold(filter.Order, "desc") {
+		order = "DESC"
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT 
+    							id, name, experience, breed, COALESCE(breed_id, ''), salary, version, created_at, updated_at, deleted_at 
+							FROM 
+							    cats 
+							WHERE 
+							    %s
+							ORDER BY 
+							    %s %s, id %s
+							LIMIT $%d OFFSET $%d`, where, sortColumn, order, order, len(args)-1, len(args))
+
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("could not list cats: %v", err)
+		return nil, 0, fmt.Errorf("could not list cats: %w", err)
 	}
 	defer rows.Close()
 
+	cats := []models.Cat{}
 	for rows.Next() {
 		var cat models.Cat
-		var updatedAt sql.NullTime
-		err = rows.Scan(&cat.ID, &cat.Name, &cat.Experience, &cat.Breed, &cat.Salary, &cat.CreatedAt, &updatedAt)
+		err = rows.Scan(&cat.ID, &cat.Name, &cat.Experience, &cat.Breed, &cat.BreedID, &cat.Salary, &cat.Version, &cat.CreatedAt, &cat.UpdatedAt, &cat.DeletedAt)
 		if err != nil {
-			return nil, fmt.Errorf("could not scan row: %v", err)
-		}
-
-		if updatedAt.Valid {
-			cat.UpdatedAt = updatedAt.Time
-		} else {
-			cat.UpdatedAt = time.Time{}
+			return nil, 0, fmt.Errorf("could not scan row: %w", err)
 		}
 
 		cats = append(cats, cat)
 	}
 
-	return cats, nil
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return cats, total, nil
 }
 
-func (repo *CatRepository) GetCatByID(id uint) (*models.Cat, error) {
+func (repo *CatRepository) GetCatByID(ctx context.Context, id uint) (*models.Cat, error) {
 	var cat models.Cat
 	query := `	SELECT 
-				    id, name, experience, breed, salary, created_at, updated_at
+				    id, name, experience, breed, COALESCE(breed_id, ''), salary, version, created_at, updated_at
 				FROM 
 				    cats 
 				WHERE 
 				    id = $1 AND deleted_at IS NULL`
-	err := repo.DB.QueryRow(query, id).Scan(&cat.ID, &cat.Name, &cat.Experience, &cat.Breed, &cat.Salary, &cat.CreatedAt, &cat.UpdatedAt)
+	err := repo.DB.QueryRowContext(ctx, query, id).Scan(&cat.ID, &cat.Name, &cat.Experience, &cat.Breed, &cat.BreedID, &cat.Salary, &cat.Version, &cat.CreatedAt, &cat.UpdatedAt)
 	if err != nil {
-		return nil, fmt.Errorf("could not get cat: %v", err)
+		return nil, notFoundOr(err, ErrCatNotFound, "could not get cat")
 	}
 	return &cat, nil
 }
 
-func (repo *CatRepository) UpdateCat(cat *models.Cat) error {
-	query := `	UPDATE 
+func (repo *CatRepository) UpdateCat(ctx context.Context, cat *models.Cat) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current models.Cat
+	query := `SELECT name, experience, breed, salary, version FROM cats WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, cat.ID).Scan(&current.Name, &current.Experience, &current.Breed, &current.Salary, &current.Version)
+	if err != nil {
+		return notFoundOr(err, ErrCatNotFound, "could not get cat")
+	}
+	if err = CheckVersion(cat.Version, current.Version); err != nil {
+		return err
+	}
+
+	query = `	UPDATE 
 				    cats 
 				SET 
-				    salary = $1, updated_at = $2 
+				    name = $1, experience = $2, breed = $3, breed_id = $4, salary = $5, updated_at = $6, version = version + 1 
 				WHERE 
-				    id = $3`
-	_, err := repo.DB.Exec(query, cat.Salary, time.Now(), cat.ID)
-	return err
+				    id = $7
+				RETURNING 
+				    updated_at, version`
+	err = tx.QueryRowContext(ctx, query, cat.Name, cat.Experience, cat.Breed, cat.BreedID, cat.Salary, time.Now(), cat.ID).Scan(&cat.UpdatedAt, &cat.Version)
+	if err != nil {
+		return fmt.Errorf("could not update cat: %w", err)
+	}
+
+	changes := catChanges(&current, cat)
+	if err = changes.record(ctx, tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit cat update: %w", err)
+	}
+	return nil
 }
 
-func (repo *CatRepository) DeleteCat(cat *models.Cat) error {
-	query := `	UPDATE 
+// DeleteCat soft-deletes a cat. An active mission of the cat is reassigned or
+// unassigned as the retirement says, in the same transaction.
+func (repo *CatRepository) DeleteCat(ctx context.Context, cat *models.Cat, retirement models.CatRetirement) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var catID uint
+	var version int
+	err = tx.QueryRowContext(ctx, `SELECT id, version FROM cats WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, cat.ID).Scan(&catID, &version)
+	if err != nil {
+		return notFoundOr(err, ErrCatNotFound, "could not get cat")
+	}
+	if err = CheckVersion(cat.Version, version); err != nil {
+		return err
+	}
+
+	var missionID uint
+	var status models.MissionStatus
+	query := `SELECT id, status FROM missions WHERE cat_id = $1 AND ` + activeMissionCondition + ` FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, catID).Scan(&missionID, &status)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error checking active mission: %w", err)
+	}
+
+	if missionID != 0 {
+		switch {
+		case retirement.ReassignTo != 0:
+			err = reassignMission(ctx, tx, missionID, catID, retirement.ReassignTo)
+		case retirement.Unassign:
+			err = unassignMission(ctx, tx, missionID, catID, status)
+		default:
+			err = errCatOnMission(missionID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `	UPDATE 
 				    cats 
 				SET 
-				    deleted_at = $1 
+				    deleted_at = $1, version = version + 1 
 				WHERE 
 				    id = $2`
-	_, err := repo.DB.Exec(query, time.Now(), cat.ID)
-	return err
+	if _, err = tx.ExecContext(ctx, query, time.Now(), catID); err != nil {
+		return fmt.Errorf("could not delete cat: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit cat deletion: %w", err)
+	}
+	return nil
+}
+
+// reassignMission hands a mission over from one cat to another live cat without an active mission.
+func reassignMission(ctx context.Context, tx *sql.Tx, missionID, fromCatID, catID uint) error {
+	var existingCatID uint
+	err := tx.QueryRowContext(ctx, `SELECT id FROM cats WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, catID).Scan(&existingCatID)
+	if err != nil {
+		return notFoundOr(err, errInvalidReassignCat(catID), "error checking cat")
+	}
+
+	var activeMissionCount int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM missions WHERE cat_id = $1 AND `+activeMissionCondition, catID).Scan(&activeMissionCount)
+	if err != nil {
+		return fmt.Errorf("error checking active mission: %w", err)
+	}
+	if activeMissionCount > 0 {
+		return ErrCatHasActiveMission
+	}
+
+	query := `UPDATE missions SET previous_cat_id = cat_id, cat_id = $1, updated_at = $2, version = version + 1 WHERE id = $3`
+	_, err = tx.ExecContext(ctx, query, catID, time.Now(), missionID)
+	if err != nil {
+		if isUniqueViolation(err, activeMissionIndex) {
+			return ErrCatHasActiveMission
+		}
+		return fmt.Errorf("could not reassign mission: %w", err)
+	}
+
+	var changes auditChanges
+	changes.missionCat(missionID, fromCatID, catID)
+	return changes.record(ctx, tx)
+}
+
+// unassignMission takes the cat off a mission and returns the mission to the draft status.
+func unassignMission(ctx context.Context, tx *sql.Tx, missionID, catID uint, status models.MissionStatus) error {
+	query := `UPDATE missions SET previous_cat_id = cat_id, cat_id = NULL, status = $1, updated_at = $2, version = version + 1 WHERE id = $3`
+	if _, err := tx.ExecContext(ctx, query, models.MissionDraft, time.Now(), missionID); err != nil {
+		return fmt.Errorf("could not unassign mission: %w", err)
+	}
+
+	var changes auditChanges
+	changes.missionCat(missionID, catID, 0)
+	changes.add(models.AuditMission, missionID, missionID, "status", status, models.MissionDraft)
+	return changes.record(ctx, tx)
+}
+
+// RestoreCat clears the deleted_at of a soft-deleted cat.
+func (repo *CatRepository) RestoreCat(ctx context.Context, id uint) error {
+	var deletedAt *time.Time
+	err := repo.DB.QueryRowContext(ctx, `SELECT deleted_at FROM cats WHERE id = $1`, id).Scan(&deletedAt)
+	if err != nil {
+		return notFoundOr(err, ErrCatNotFound, "could not get cat")
+	}
+	if deletedAt == nil {
+		return errNotDeleted("cat")
+	}
+
+	_, err = repo.DB.ExecContext(ctx, `UPDATE cats SET deleted_at = NULL, updated_at = $1, version = version + 1 WHERE id = $2`, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("could not restore cat: %w", err)
+	}
+	return nil
 }
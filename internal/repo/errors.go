@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"database/sql"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/models"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+)
+
+// Errors shared by the Postgres and in-memory stores.
+var (
+	ErrCatNotFound         = apperr.NotFound("cat_not_found", "cat not found")
+	ErrMissionNotFound     = apperr.NotFound("mission_not_found", "mission not found")
+	ErrTargetNotFound      = apperr.NotFound("target_not_found", "target not found")
+	ErrNoteNotFound        = apperr.NotFound("note_not_found", "note revision not found")
+	ErrCatHasActiveMission = apperr.Conflict("cat_has_active_mission", "this cat already has an active mission")
+	ErrMissionAssigned     = apperr.Conflict("mission_assigned", "cannot delete mission assigned to a cat")
+	ErrMissionUnassigned   = apperr.Conflict("mission_unassigned", "mission has no cat assigned")
+	ErrTargetComplete      = apperr.Conflict("target_complete", "target is complete")
+	ErrAPIKeyNotFound      = apperr.NotFound("api_key_not_found", "API key not found or already revoked")
+	ErrAPIKeyNameTaken     = apperr.Conflict("api_key_name_taken", "an API key with this name is already in use")
+	// ErrTooManyTargets shares its code with the target count check on mission creation.
+	ErrTooManyTargets = apperr.Validation("invalid_target_count", "a mission must have between %d and %d targets, mission already has %d",
+		models.MinTargetsPerMission, models.MaxTargetsPerMission, models.MaxTargetsPerMission)
+	ErrTooFewTargets = apperr.Validation("invalid_target_count", "a mission must have between %d and %d targets, mission only has %d",
+		models.MinTargetsPerMission, models.MaxTargetsPerMission, models.MinTargetsPerMission)
+)
+
+// errCatOnMission is returned when deleting a cat that still has an active mission.
+func errCatOnMission(missionID uint) error {
+	return apperr.Conflict("cat_on_active_mission", "cat has active mission %d; reassign or unassign it first", missionID).
+		WithDetails(map[string]interface{}{"mission_id": missionID})
+}
+
+// errInvalidReassignCat is returned when a retired cat's mission cannot be handed to the chosen cat.
+func errInvalidReassignCat(catID uint) error {
+	return apperr.Validation("invalid_reassign_to", "cat %d does not exist", catID).
+		WithFields(apperr.FieldError{Field: "reassign_to", Message: "must be an existing cat"})
+}
+
+// errInvalidNoteAuthor is returned when a note names an author cat that does not exist.
+func errInvalidNoteAuthor(catID uint) error {
+	return apperr.Validation("invalid_author_cat_id", "cat %d does not exist", catID).
+		WithFields(apperr.FieldError{Field: "author_cat_id", Message: "must be an existing cat"})
+}
+
+// errVersionMismatch is returned when an If-Match version no longer matches the row.
+func errVersionMismatch(current int) error {
+	return apperr.PreconditionFailed("version_mismatch", "resource was modified; current version is %d", current).
+		WithDetails(map[string]interface{}{"version": current})
+}
+
+// CheckVersion compares the version a client expects with the current one; 0 matches any version.
+func CheckVersion(expected, current int) error {
+	if expected != 0 && expected != current {
+		return errVersionMismatch(current)
+	}
+	return nil
+}
+
+// errMissionClosed is returned when changing a mission, or one of its targets, after it was completed or aborted.
+func errMissionClosed(status models.MissionStatus) error {
+	return apperr.Conflict("mission_closed", "mission is %s", status)
+}
+
+// errOpenTargets is returned when completing a mission that still has open targets.
+func errOpenTargets(open int) error {
+	return apperr.Conflict("open_targets", "mission still has %d open target(s)", open)
+}
+
+// errNotDeleted is returned when restoring an entity that is not soft-deleted.
+func errNotDeleted(entity string) error {
+	return apperr.Conflict(entity+"_not_deleted", "%s is not deleted", entity)
+}
+
+// isUniqueViolation reports whether err is a Postgres unique violation on the given constraint.
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// notFoundOr maps sql.ErrNoRows to notFound and wraps any other error.
+func notFoundOr(err error, notFound error, action string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
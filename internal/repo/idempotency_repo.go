@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"devTodTestTask/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type IdempotencyRepository struct {
+	DB *sql.DB
+}
+
+// ReserveIdempotencyKey claims a key for a request about to be handled. Keys
+// created before expiredBefore are free to be claimed again, and so are
+// reservations without a response made before staleBefore, whose request
+// must have died. When the key is already held it returns the stored record
+// and leaves the table unchanged.
+func (repo *IdempotencyRepository) ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, staleBefore time.Time) (*models.IdempotencyRecord, error) {
+	query := `INSERT INTO idempotency_keys (subject, idempotency_key, request_hash, created_at)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (subject, idempotency_key) DO UPDATE
+			  SET request_hash = EXCLUDED.request_hash, status_code = NULL, headers = NULL, body = NULL, created_at = EXCLUDED.created_at
+			  WHERE idempotency_keys.created_at < $5 
+			     OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < $6)
+			  RETURNING created_at`
+	err := repo.DB.QueryRowContext(ctx, query, record.Subject, record.Key, record.RequestHash, time.Now(), expiredBefore, staleBefore).
+		Scan(&record.CreatedAt)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("could not reserve idempotency key: %w", err)
+	}
+
+	existing := models.IdempotencyRecord{Subject: record.Subject, Key: record.Key}
+	var header []byte
+	query = `SELECT request_hash, COALESCE(status_code, 0), headers, body, created_at
+			 FROM idempotency_keys
+			 WHERE subject = $1 AND idempotency_key = $2`
+	err = repo.DB.QueryRowContext(ctx, query, record.Subject, record.Key).
+		Scan(&existing.RequestHash, &existing.StatusCode, &header, &existing.Body, &existing.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The holder released the key in between; report it as still in use so the client retries.
+		existing.RequestHash = record.RequestHash
+		return &existing, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get idempotency key: %w", err)
+	}
+	if header != nil {
+		if err = json.Unmarshal(header, &existing.Header); err != nil {
+			return nil, fmt.Errorf("could not decode idempotent response headers: %w", err)
+		}
+	}
+	return &existing, nil
+}
+
+// SaveIdempotentResponse stores the response to the request holding the key.
+// It does nothing once the reservation was taken over by another request.
+func (repo *IdempotencyRepository) SaveIdempotentResponse(ctx context.Context, record *models.IdempotencyRecord) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return fmt.Errorf("could not encode idempotent response headers: %w", err)
+	}
+
+	query := `UPDATE idempotency_keys SET status_code = $1, headers = $2, body = $3
+			  WHERE subject = $4 AND idempotency_key = $5 AND created_at = $6`
+	_, err = repo.DB.ExecContext(ctx, query, record.StatusCode, header, record.Body, record.Subject, record.Key, record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("could not save idempotent response: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey frees a key whose request did not succeed, so that it can be retried.
+func (repo *IdempotencyRepository) ReleaseIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	query := `DELETE FROM idempotency_keys WHERE subject = $1 AND idempotency_key = $2 AND created_at = $3 AND status_code IS NULL`
+	if _, err := repo.DB.ExecContext(ctx, query, record.Subject, record.Key, record.CreatedAt); err != nil {
+		return fmt.Errorf("could not release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys removes keys created before the cutoff.
+func (repo *IdempotencyRepository) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	res, err := repo.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("could not purge idempotency keys: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+)
+
+func (s *MemoryStore) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.apiKeys {
+		if existing.Name == key.Name && existing.RevokedAt == nil {
+			return ErrAPIKeyNameTaken
+		}
+	}
+
+	key.ID = uint(len(s.apiKeys) + 1)
+	key.CreatedAt = timestamp()
+	stored := *key
+	s.apiKeys = append(s.apiKeys, &stored)
+	return nil
+}
+
+func (s *MemoryStore) UseAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.apiKeys {
+		if key.KeyHash == keyHash && key.RevokedAt == nil {
+			key.LastUsedAt = timestamp()
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func (s *MemoryStore) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []models.APIKey{}
+	for _, key := range s.apiKeys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (s *MemoryStore) RevokeAPIKey(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.apiKeys {
+		if key.ID == id && key.RevokedAt == nil {
+			key.RevokedAt = timestamp()
+			return nil
+		}
+	}
+	return ErrAPIKeyNotFound
+}
@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"strconv"
+)
+
+func (s *MemoryStore) MissionHistory(ctx context.Context, missionID uint) ([]models.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []models.AuditEvent{}
+	for _, event := range s.events {
+		if event.MissionID == missionID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *MemoryStore) CatHistory(ctx context.Context, catID uint) ([]models.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := strconv.FormatUint(uint64(catID), 10)
+	events := []models.AuditEvent{}
+	for _, event := range s.events {
+		isCat := event.EntityType == models.AuditCat && event.EntityID == catID
+		isAssignment := event.EntityType == models.AuditMission && event.Field == "cat_id" &&
+			(sameValue(event.OldValue, &id) || sameValue(event.NewValue, &id))
+		if isCat || isAssignment {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"sort"
+	"strings"
+)
+
+func (s *MemoryStore) CreateCat(ctx context.Context, cat *models.Cat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastCatID++
+	cat.ID = int(s.lastCatID)
+	cat.CreatedAt = timestamp()
+	cat.Version = 1
+	stored := *cat
+	s.cats[s.lastCatID] = &stored
+	return nil
+}
+
+func (s *MemoryStore) ListCats(ctx context.Context, filter models.CatFilter) ([]models.Cat, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cats := []models.Cat{}
+	for _, cat := range s.cats {
+		if cat.DeletedAt != nil && !filter.IncludeDeleted {
+			continue
+		}
+		if filter.Breed != "" && !strings.EqualFold(cat.Breed, strings.TrimSpace(filter.Breed)) {
+			continue
+		}
+		if filter.MinExperience != nil && cat.Experience < *filter.MinExperience {
+			continue
+		}
+		if filter.MaxExperience != nil && cat.Experience > *filter.MaxExperience {
+			continue
+		}
+		if filter.MinSalary != nil && cat.Salary < *filter.MinSalary {
+			continue
+		}
+		if filter.MaxSalary != nil && cat.Salary > *filter.MaxSalary {
+			continue
+		}
+		cats = append(cats, *cat)
+	}
+
+	less := catLess(filter.SortBy)
+	desc := strings.EqualFold(filter.Order, "desc")
+	sort.Slice(cats, func(i, j int) bool {
+		a, b := cats[i], cats[j]
+		if desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.ID < b.ID
+	})
+
+	start, end := page(filter.Page, len(cats))
+	return cats[start:end], len(cats), nil
+}
+
+// catLess returns the ordering for a sort_by value, mirroring catSortColumns.
+func catLess(sortBy string) func(a, b models.Cat) bool {
+	switch sortBy {
+	case "name":
+		return func(a, b models.Cat) bool { return a.Name < b.Name }
+	case "experience":
+		return func(a, b models.Cat) bool { return a.Experience < b.Experience }
+	case "breed":
+		return func(a, b models.Cat) bool { return a.Breed < b.Breed }
+	case "salary":
+		return func(a, b models.Cat) bool { return a.Salary < b.Salary }
+	case "created_at":
+		return func(a, b models.Cat) bool { return before(a.CreatedAt, b.CreatedAt) }
+	case "updated_at":
+		return func(a, b models.Cat) bool { return before(a.UpdatedAt, b.UpdatedAt) }
+	default:
+		return func(a, b models.Cat) bool { return a.ID < b.ID }
+	}
+}
+
+func (s *MemoryStore) GetCatByID(ctx context.Context, id uint) (*models.Cat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cat, ok := s.liveCat(id)
+	if !ok {
+		return nil, ErrCatNotFound
+	}
+	found := *cat
+	return &found, nil
+}
+
+func (s *MemoryStore) UpdateCat(ctx context.Context, cat *models.Cat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.liveCat(uint(cat.ID))
+	if !ok {
+		return ErrCatNotFound
+	}
+	if err := CheckVersion(cat.Version, stored.Version); err != nil {
+		return err
+	}
+	s.record(ctx, catChanges(stored, cat))
+
+	stored.Name = cat.Name
+	stored.Experience = cat.Experience
+	stored.Breed = cat.Breed
+	stored.BreedID = cat.BreedID
+	stored.Salary = cat.Salary
+	stored.UpdatedAt = timestamp()
+	stored.Version++
+	cat.UpdatedAt = stored.UpdatedAt
+	cat.Version = stored.Version
+	return nil
+}
+
+func (s *MemoryStore) DeleteCat(ctx context.Context, cat *models.Cat, retirement models.CatRetirement) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.liveCat(uint(cat.ID))
+	if !ok {
+		return ErrCatNotFound
+	}
+	if err := CheckVersion(cat.Version, stored.Version); err != nil {
+		return err
+	}
+
+	if mission := s.activeMission(uint(cat.ID)); mission != nil {
+		switch {
+		case retirement.ReassignTo != 0:
+			if _, ok := s.liveCat(retirement.ReassignTo); !ok {
+				return errInvalidReassignCat(retirement.ReassignTo)
+			}
+			if s.hasActiveMission(retirement.ReassignTo) {
+				return ErrCatHasActiveMission
+			}
+			var changes auditChanges
+			changes.missionCat(mission.ID, mission.CatID, retirement.ReassignTo)
+			s.record(ctx, changes)
+
+			mission.PreviousCatID = mission.CatID
+			mission.CatID = retirement.ReassignTo
+			mission.UpdatedAt = timestamp()
+			mission.Version++
+		case retirement.Unassign:
+			s.unassign(ctx, mission)
+		default:
+			return errCatOnMission(mission.ID)
+		}
+	}
+
+	stored.DeletedAt = timestamp()
+	stored.Version++
+	return nil
+}
+
+func (s *MemoryStore) RestoreCat(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cat, ok := s.cats[id]
+	if !ok {
+		return ErrCatNotFound
+	}
+	if cat.DeletedAt == nil {
+		return errNotDeleted("cat")
+	}
+	cat.DeletedAt = nil
+	cat.UpdatedAt = timestamp()
+	cat.Version++
+	return nil
+}
@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"time"
+)
+
+type idempotencyKey struct {
+	subject string
+	key     string
+}
+
+func (s *MemoryStore) ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, staleBefore time.Time) (*models.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKey{record.Subject, record.Key}
+	if existing, ok := s.idempotency[id]; ok && !existing.CreatedAt.Before(expiredBefore) {
+		if existing.StatusCode != 0 || !existing.CreatedAt.Before(staleBefore) {
+			found := *existing
+			return &found, nil
+		}
+	}
+
+	record.CreatedAt = timestamp()
+	reserved := *record
+	reserved.StatusCode, reserved.Header, reserved.Body = 0, nil, nil
+	s.idempotency[id] = &reserved
+	return nil, nil
+}
+
+func (s *MemoryStore) SaveIdempotentResponse(ctx context.Context, record *models.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.idempotency[idempotencyKey{record.Subject, record.Key}]; ok && sameReservation(existing, record) {
+		existing.StatusCode, existing.Header, existing.Body = record.StatusCode, record.Header, record.Body
+	}
+	return nil
+}
+
+func (s *MemoryStore) ReleaseIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := idempotencyKey{record.Subject, record.Key}
+	if existing, ok := s.idempotency[id]; ok && existing.StatusCode == 0 && sameReservation(existing, record) {
+		delete(s.idempotency, id)
+	}
+	return nil
+}
+
+// sameReservation reports whether record still holds the reservation stored as
+// existing, rather than a request that took it over later.
+func sameReservation(existing, record *models.IdempotencyRecord) bool {
+	return record.CreatedAt != nil && existing.CreatedAt.Equal(*record.CreatedAt)
+}
+
+func (s *MemoryStore) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for id, record := range s.idempotency {
+		if record.CreatedAt.Before(before) {
+			delete(s.idempotency, id)
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,417 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"sort"
+)
+
+func (s *MemoryStore) CreateMission(ctx context.Context, mission *models.Mission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Missions without a cat go to the unassigned pool
+	if mission.CatID != 0 {
+		if _, ok := s.liveCat(mission.CatID); !ok {
+			return ErrCatNotFound
+		}
+
+		// If the cat already has an active mission, prevent creating a new one
+		if s.hasActiveMission(mission.CatID) {
+			return ErrCatHasActiveMission
+		}
+	}
+
+	createdAt := timestamp()
+	s.lastMissionID++
+	mission.ID = s.lastMissionID
+	mission.IsComplete = mission.Status == models.MissionCompleted
+	mission.CreatedAt = createdAt
+	mission.Version = 1
+
+	for i := range mission.Targets {
+		s.lastTargetID++
+		target := &mission.Targets[i]
+		target.ID = int(s.lastTargetID)
+		target.MissionID = mission.ID
+		target.CreatedAt = createdAt
+		target.Version = 1
+		stored := *target
+		s.targets[s.lastTargetID] = &stored
+		s.addInitialNote(target)
+	}
+
+	stored := *mission
+	stored.Targets = nil
+	s.missions[mission.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStore) ListMissions(ctx context.Context, filter models.MissionFilter) ([]models.Mission, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	missions := []models.Mission{}
+	for _, mission := range s.missions {
+		if mission.DeletedAt != nil && !filter.IncludeDeleted {
+			continue
+		}
+		if filter.IsComplete != nil && mission.IsComplete != *filter.IsComplete {
+			continue
+		}
+		if filter.Status != "" && string(mission.Status) != filter.Status {
+			continue
+		}
+		if filter.CatID != nil && mission.CatID != *filter.CatID {
+			continue
+		}
+		if filter.CreatedFrom != nil && mission.CreatedAt.Before(*filter.CreatedFrom) {
+			continue
+		}
+		if filter.CreatedTo != nil && mission.CreatedAt.After(*filter.CreatedTo) {
+			continue
+		}
+		if filter.Unassigned != nil && (mission.CatID == 0) != *filter.Unassigned {
+			continue
+		}
+		missions = append(missions, *mission)
+	}
+	sort.Slice(missions, func(i, j int) bool { return missions[i].ID < missions[j].ID })
+
+	total := len(missions)
+	start, end := page(filter.Page, total)
+	missions = missions[start:end]
+	for i := range missions {
+		missions[i].Targets = s.missionTargets(missions[i].ID, filter.IncludeDeleted)
+	}
+	return missions, total, nil
+}
+
+func (s *MemoryStore) GetMissionByID(ctx context.Context, id uint) (*models.Mission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.liveMission(id)
+	if !ok {
+		return nil, ErrMissionNotFound
+	}
+	mission := *stored
+	mission.Targets = s.missionTargets(id, false)
+	return &mission, nil
+}
+
+func (s *MemoryStore) SetMissionStatus(ctx context.Context, id uint, version int, status models.MissionStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mission, ok := s.liveMission(id)
+	if !ok {
+		return ErrMissionNotFound
+	}
+	if err := CheckVersion(version, mission.Version); err != nil {
+		return err
+	}
+	if status == models.MissionCompleted {
+		if open := s.countTargets(id, true); open > 0 {
+			return errOpenTargets(open)
+		}
+	}
+	var changes auditChanges
+	s.setStatus(mission, status, &changes)
+	s.record(ctx, changes)
+	return nil
+}
+
+// setStatus moves a mission to another status.
+func (s *MemoryStore) setStatus(mission *models.Mission, status models.MissionStatus, changes *auditChanges) {
+	changes.add(models.AuditMission, mission.ID, mission.ID, "status", mission.Status, status)
+
+	now := timestamp()
+	mission.Status = status
+	mission.IsComplete = status == models.MissionCompleted
+	if mission.IsComplete {
+		if mission.CompletedAt == nil {
+			mission.CompletedAt = now
+		}
+	} else {
+		mission.CompletedAt = nil
+	}
+	mission.UpdatedAt = now
+	mission.Version++
+}
+
+func (s *MemoryStore) DeleteMission(ctx context.Context, id uint, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mission, ok := s.liveMission(id)
+	if !ok {
+		return ErrMissionNotFound
+	}
+	if err := CheckVersion(version, mission.Version); err != nil {
+		return err
+	}
+
+	// Prevent deleting a mission that has a cat assigned
+	if mission.CatID != 0 {
+		return ErrMissionAssigned
+	}
+
+	mission.DeletedAt = timestamp()
+	mission.Version++
+	return nil
+}
+
+func (s *MemoryStore) AddTargetToMission(ctx context.Context, missionID uint, target *models.Target) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mission, ok := s.liveMission(missionID)
+	if !ok {
+		return ErrMissionNotFound
+	}
+
+	// Prevent adding targets to completed or aborted missions
+	if mission.Status.Closed() {
+		return errMissionClosed(mission.Status)
+	}
+
+	if s.countTargets(missionID, false) >= models.MaxTargetsPerMission {
+		return ErrTooManyTargets
+	}
+
+	now := timestamp()
+	s.lastTargetID++
+	target.ID = int(s.lastTargetID)
+	target.MissionID = missionID
+	target.CreatedAt = now
+	target.UpdatedAt = now
+	target.Version = 1
+	stored := *target
+	s.targets[s.lastTargetID] = &stored
+	s.addInitialNote(target)
+	return nil
+}
+
+func (s *MemoryStore) AssignCatToMission(ctx context.Context, missionID, catID uint, version int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mission, ok := s.missions[missionID]
+	if !ok || !isActiveMission(mission) {
+		return 0, ErrMissionNotFound
+	}
+	if err := CheckVersion(version, mission.Version); err != nil {
+		return 0, err
+	}
+
+	if _, ok := s.liveCat(catID); !ok {
+		return 0, ErrCatNotFound
+	}
+
+	// Prevent assigning a cat that already has an active mission
+	if s.hasActiveMission(catID) {
+		return 0, ErrCatHasActiveMission
+	}
+
+	var changes auditChanges
+	changes.missionCat(missionID, mission.CatID, catID)
+	if mission.Status == models.MissionDraft {
+		changes.add(models.AuditMission, missionID, missionID, "status", mission.Status, models.MissionAssigned)
+		mission.Status = models.MissionAssigned
+	}
+	s.record(ctx, changes)
+
+	if mission.CatID != 0 {
+		mission.PreviousCatID = mission.CatID
+	}
+	mission.CatID = catID
+	mission.UpdatedAt = timestamp()
+	mission.Version++
+	return mission.Version, nil
+}
+
+func (s *MemoryStore) UnassignCatFromMission(ctx context.Context, missionID uint, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mission, ok := s.missions[missionID]
+	if !ok || !isActiveMission(mission) {
+		return ErrMissionNotFound
+	}
+	if err := CheckVersion(version, mission.Version); err != nil {
+		return err
+	}
+	if mission.CatID == 0 {
+		return ErrMissionUnassigned
+	}
+
+	s.unassign(ctx, mission)
+	return nil
+}
+
+// unassign takes the cat off a mission and returns the mission to the draft status.
+func (s *MemoryStore) unassign(ctx context.Context, mission *models.Mission) {
+	var changes auditChanges
+	changes.missionCat(mission.ID, mission.CatID, 0)
+	changes.add(models.AuditMission, mission.ID, mission.ID, "status", mission.Status, models.MissionDraft)
+	s.record(ctx, changes)
+
+	mission.PreviousCatID = mission.CatID
+	mission.CatID = 0
+	mission.Status = models.MissionDraft
+	mission.UpdatedAt = timestamp()
+	mission.Version++
+}
+
+func (s *MemoryStore) UpdateTargetStatus(ctx context.Context, target *models.Target) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.openTarget(uint(target.ID), target.Version)
+	if err != nil {
+		return err
+	}
+	// A draft has no cat to do the work; its targets wait until one is assigned
+	if s.missions[stored.MissionID].Status == models.MissionDraft {
+		return ErrMissionUnassigned
+	}
+	target.MissionID = stored.MissionID
+
+	var changes auditChanges
+	changes.add(models.AuditTarget, uint(target.ID), stored.MissionID, "is_complete", stored.IsComplete, target.IsComplete)
+
+	stored.IsComplete = target.IsComplete
+	stored.UpdatedAt = timestamp()
+	stored.Version++
+	target.Version = stored.Version
+
+	// Start the mission with its first completed target and complete it with the last one
+	if target.IsComplete {
+		mission := s.missions[stored.MissionID]
+		next := models.MissionInProgress
+		if s.countTargets(mission.ID, true) == 0 {
+			next = models.MissionCompleted
+		}
+		if mission.Status.CanTransitionTo(next) {
+			s.setStatus(mission, next, &changes)
+		}
+	}
+	s.record(ctx, changes)
+	return nil
+}
+
+// openTarget returns a target that may still be changed: neither the target
+// nor its mission is deleted or complete, and a non-zero version still matches.
+func (s *MemoryStore) openTarget(id uint, version int) (*models.Target, error) {
+	target, ok := s.targets[id]
+	if !ok || target.DeletedAt != nil {
+		return nil, ErrTargetNotFound
+	}
+	if err := CheckVersion(version, target.Version); err != nil {
+		return nil, err
+	}
+	if target.IsComplete {
+		return nil, ErrTargetComplete
+	}
+
+	mission, ok := s.liveMission(target.MissionID)
+	if !ok {
+		return nil, ErrMissionNotFound
+	}
+	if mission.Status.Closed() {
+		return nil, errMissionClosed(mission.Status)
+	}
+	return target, nil
+}
+
+func (s *MemoryStore) DeleteTarget(ctx context.Context, id uint, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.targets[id]
+	if !ok || target.DeletedAt != nil {
+		return ErrTargetNotFound
+	}
+	if err := CheckVersion(version, target.Version); err != nil {
+		return err
+	}
+
+	// Prevent deleting a completed target
+	if target.IsComplete {
+		return ErrTargetComplete
+	}
+
+	// Prevent deleting the last target of a mission
+	if s.countTargets(target.MissionID, false) <= models.MinTargetsPerMission {
+		return ErrTooFewTargets
+	}
+
+	target.DeletedAt = timestamp()
+	target.Version++
+	return nil
+}
+
+func (s *MemoryStore) GetTargetByID(ctx context.Context, id uint) (*models.Target, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.targets[id]
+	if !ok || target.DeletedAt != nil {
+		return nil, ErrTargetNotFound
+	}
+	copied := *target
+	return &copied, nil
+}
+
+func (s *MemoryStore) RestoreMission(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mission, ok := s.missions[id]
+	if !ok {
+		return ErrMissionNotFound
+	}
+	if mission.DeletedAt == nil {
+		return errNotDeleted("mission")
+	}
+
+	// An active mission may only come back while its cat has no other one
+	if !mission.Status.Closed() && mission.CatID != 0 && s.hasActiveMission(mission.CatID) {
+		return ErrCatHasActiveMission
+	}
+
+	mission.DeletedAt = nil
+	mission.UpdatedAt = timestamp()
+	mission.Version++
+	return nil
+}
+
+func (s *MemoryStore) RestoreTarget(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.targets[id]
+	if !ok {
+		return ErrTargetNotFound
+	}
+	if target.DeletedAt == nil {
+		return errNotDeleted("target")
+	}
+
+	mission, ok := s.liveMission(target.MissionID)
+	if !ok {
+		return ErrMissionNotFound
+	}
+	if mission.Status.Closed() {
+		return errMissionClosed(mission.Status)
+	}
+	if s.countTargets(mission.ID, false) >= models.MaxTargetsPerMission {
+		return ErrTooManyTargets
+	}
+
+	target.DeletedAt = nil
+	target.UpdatedAt = timestamp()
+	target.Version++
+	return nil
+}
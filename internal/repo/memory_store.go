@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps cats, missions and targets in memory. It follows the same
+// rules as the Postgres repositories (soft deletes, the target cap and one
+// active mission per cat) so services and handlers can be exercised offline.
+type MemoryStore struct {
+	mu       sync.Mutex
+	cats     map[uint]*models.Cat
+	missions map[uint]*models.Mission
+	targets  map[uint]*models.Target
+	notes    map[uint][]models.TargetNote
+	events   []models.AuditEvent
+
+	idempotency map[idempotencyKey]*models.IdempotencyRecord
+	apiKeys     []*models.APIKey
+
+	lastCatID     uint
+	lastMissionID uint
+	lastTargetID  uint
+	lastNoteID    uint
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		cats:     make(map[uint]*models.Cat),
+		missions: make(map[uint]*models.Mission),
+		targets:  make(map[uint]*models.Target),
+		notes:    make(map[uint][]models.TargetNote),
+
+		idempotency: make(map[idempotencyKey]*models.IdempotencyRecord),
+	}
+}
+
+// liveCat returns the cat with the given ID unless it does not exist or is soft-deleted.
+func (s *MemoryStore) liveCat(id uint) (*models.Cat, bool) {
+	cat, ok := s.cats[id]
+	if !ok || cat.DeletedAt != nil {
+		return nil, false
+	}
+	return cat, true
+}
+
+// hasActiveMission reports whether the cat has a non-deleted mission that is not closed.
+func (s *MemoryStore) hasActiveMission(catID uint) bool {
+	return s.activeMission(catID) != nil
+}
+
+// activeMission returns the cat's active mission, or nil.
+func (s *MemoryStore) activeMission(catID uint) *models.Mission {
+	for _, mission := range s.missions {
+		if mission.CatID == catID && isActiveMission(mission) {
+			return mission
+		}
+	}
+	return nil
+}
+
+func isActiveMission(mission *models.Mission) bool {
+	return !mission.Status.Closed() && mission.DeletedAt == nil
+}
+
+// liveMission returns the mission with the given ID unless it does not exist or is soft-deleted.
+func (s *MemoryStore) liveMission(id uint) (*models.Mission, bool) {
+	mission, ok := s.missions[id]
+	if !ok || mission.DeletedAt != nil {
+		return nil, false
+	}
+	return mission, true
+}
+
+// missionTargets returns copies of the targets of a mission ordered by ID,
+// leaving out soft-deleted targets unless includeDeleted is set.
+func (s *MemoryStore) missionTargets(missionID uint, includeDeleted bool) []models.Target {
+	var targets []models.Target
+	for id := uint(1); id <= s.lastTargetID; id++ {
+		target, ok := s.targets[id]
+		if ok && target.MissionID == missionID && (includeDeleted || target.DeletedAt == nil) {
+			targets = append(targets, *target)
+		}
+	}
+	return targets
+}
+
+func (s *MemoryStore) countTargets(missionID uint, openOnly bool) int {
+	count := 0
+	for _, target := range s.targets {
+		if target.MissionID != missionID || target.DeletedAt != nil {
+			continue
+		}
+		if openOnly && target.IsComplete {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// record appends audit events for the changes made by a mutation.
+func (s *MemoryStore) record(ctx context.Context, changes auditChanges) {
+	changes.stamp(ctx, time.Now())
+	for _, event := range changes {
+		event.ID = int64(len(s.events) + 1)
+		s.events = append(s.events, event)
+	}
+}
+
+// page applies limit and offset to a slice length and returns the bounds to keep.
+func page(p models.Page, total int) (int, int) {
+	start := p.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if p.Limit > 0 && start+p.Limit < end {
+		end = start + p.Limit
+	}
+	return start, end
+}
+
+// timestamp returns the current time for a model's nullable timestamp fields.
+func timestamp() *time.Time {
+	now := time.Now()
+	return &now
+}
+
+// before orders nullable timestamps with NULLs last, as Postgres does in ascending order.
+func before(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a != nil
+	}
+	return a.Before(*b)
+}
+
+// PurgeDeleted removes everything soft-deleted before the cutoff, keeping cats
+// that are still referenced by a mission or a note.
+func (s *MemoryStore) PurgeDeleted(ctx context.Context, before time.Time) (PurgeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result PurgeResult
+	expired := func(deletedAt *time.Time) bool {
+		return deletedAt != nil && deletedAt.Before(before)
+	}
+
+	for id, mission := range s.missions {
+		if expired(mission.DeletedAt) {
+			delete(s.missions, id)
+			result.Missions++
+		}
+	}
+	for id, target := range s.targets {
+		_, hasMission := s.missions[target.MissionID]
+		if expired(target.DeletedAt) {
+			result.Targets++
+		}
+		if expired(target.DeletedAt) || !hasMission {
+			delete(s.targets, id)
+			delete(s.notes, id)
+		}
+	}
+
+	referenced := make(map[uint]bool)
+	for _, mission := range s.missions {
+		referenced[mission.CatID] = true
+	}
+	for _, notes := range s.notes {
+		for _, note := range notes {
+			referenced[note.AuthorCatID] = true
+		}
+	}
+	for id, cat := range s.cats {
+		if expired(cat.DeletedAt) && !referenced[id] {
+			delete(s.cats, id)
+			result.Cats++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+)
+
+func (s *MemoryStore) AddTargetNote(ctx context.Context, note *models.TargetNote, version int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, err := s.openTarget(note.TargetID, version)
+	if err != nil {
+		return 0, err
+	}
+	if note.AuthorCatID != 0 {
+		if _, ok := s.liveCat(note.AuthorCatID); !ok {
+			return 0, errInvalidNoteAuthor(note.AuthorCatID)
+		}
+	}
+
+	var changes auditChanges
+	changes.add(models.AuditTarget, note.TargetID, target.MissionID, "notes", target.Notes, note.Body)
+	s.record(ctx, changes)
+
+	s.addNote(note)
+	target.Notes = note.Body
+	target.UpdatedAt = note.CreatedAt
+	target.Version++
+	return target.Version, nil
+}
+
+// addNote stores the next revision of a target's notes.
+func (s *MemoryStore) addNote(note *models.TargetNote) {
+	s.lastNoteID++
+	note.ID = s.lastNoteID
+	note.Revision = len(s.notes[note.TargetID]) + 1
+	note.CreatedAt = timestamp()
+	s.notes[note.TargetID] = append(s.notes[note.TargetID], *note)
+}
+
+// addInitialNote records the notes a target was created with as its first revision.
+func (s *MemoryStore) addInitialNote(target *models.Target) {
+	if target.Notes == "" {
+		return
+	}
+	s.addNote(&models.TargetNote{TargetID: uint(target.ID), Body: target.Notes})
+}
+
+func (s *MemoryStore) ListTargetNotes(ctx context.Context, targetID uint) ([]models.TargetNote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if target, ok := s.targets[targetID]; !ok || target.DeletedAt != nil {
+		return nil, ErrTargetNotFound
+	}
+	notes := make([]models.TargetNote, len(s.notes[targetID]))
+	copy(notes, s.notes[targetID])
+	return notes, nil
+}
+
+func (s *MemoryStore) GetTargetNote(ctx context.Context, targetID uint, revision int) (*models.TargetNote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if target, ok := s.targets[targetID]; !ok || target.DeletedAt != nil {
+		return nil, ErrTargetNotFound
+	}
+	notes := s.notes[targetID]
+	if revision < 1 || revision > len(notes) {
+		return nil, ErrNoteNotFound
+	}
+	note := notes[revision-1]
+	return &note, nil
+}
@@ -1,9 +1,12 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"devTodTestTask/internal/models"
 	"fmt"
+	"github.com/lib/pq"
+	"strings"
 	"time"
 )
 
@@ -11,119 +14,232 @@ type MissionRepository struct {
 	DB *sql.DB
 }
 
-func (repo *MissionRepository) CreateMission(mission *models.Mission) error {
-	// Check if the cat already has an active mission
-	var activeMissionCount int
-	err := repo.DB.QueryRow(`SELECT COUNT(*) FROM missions WHERE cat_id = $1 AND is_complete = FALSE`, mission.CatID).Scan(&activeMissionCount)
+// activeMissionIndex is the unique partial index that allows one active mission per cat.
+const activeMissionIndex = "missions_one_active_per_cat_idx"
+
+// activeMissionCondition matches missions that still count as the cat's active mission.
+const activeMissionCondition = `status NOT IN ('completed', 'aborted') AND deleted_at IS NULL`
+
+func (repo *MissionRepository) CreateMission(ctx context.Context, mission *models.Mission) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("error checking active mission: %v", err)
+		return fmt.Errorf("could not start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// If the cat already has an active mission, prevent creating a new one
-	if activeMissionCount > 0 {
-		return fmt.Errorf("this cat already has an active mission")
+	// Missions without a cat go to the unassigned pool
+	if mission.CatID != 0 {
+		// Lock the cat so concurrent requests for the same cat are serialized
+		var catID uint
+		err = tx.QueryRowContext(ctx, `SELECT id FROM cats WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, mission.CatID).Scan(&catID)
+		if err != nil {
+			return notFoundOr(err, ErrCatNotFound, "error checking cat")
+		}
+
+		// Check if the cat already has an active mission
+		var activeMissionCount int
+		err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM missions WHERE cat_id = $1 AND `+activeMissionCondition, mission.CatID).Scan(&activeMissionCount)
+		if err != nil {
+			return fmt.Errorf("error checking active mission: %w", err)
+		}
+
+		// If the cat already has an active mission, prevent creating a new one
+		if activeMissionCount > 0 {
+			return ErrCatHasActiveMission
+		}
 	}
 
 	// Insert a new mission
-	query := `INSERT INTO missions (cat_id, is_complete, created_at)
-			  VALUES ($1, $2, $3) RETURNING id`
-	err = repo.DB.QueryRow(query, mission.CatID, mission.IsComplete, time.Now()).Scan(&mission.ID)
+	query := `INSERT INTO missions (cat_id, status, is_complete, created_at)
+			  VALUES (NULLIF($1, 0), $2, $3, $4) RETURNING id, created_at, version`
+	err = tx.QueryRowContext(ctx, query, mission.CatID, mission.Status, mission.Status == models.MissionCompleted, time.Now()).Scan(&mission.ID, &mission.CreatedAt, &mission.Version)
 	if err != nil {
-		return fmt.Errorf("could not create mission: %v", err)
+		if isUniqueViolation(err, activeMissionIndex) {
+			return ErrCatHasActiveMission
+		}
+		return fmt.Errorf("could not create mission: %w", err)
 	}
 
 	// Add targets to the mission
-	for _, target := range mission.Targets {
+	for i := range mission.Targets {
+		target := &mission.Targets[i]
+		target.MissionID = mission.ID
 		targetQuery := `INSERT INTO targets (mission_id, name, country, notes, is_complete, created_at)
-						VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
-		err := repo.DB.QueryRow(targetQuery, mission.ID, target.Name, target.Country, target.Notes, target.IsComplete, time.Now()).Scan(&target.ID)
+						VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, version`
+		err := tx.QueryRowContext(ctx, targetQuery, mission.ID, target.Name, target.Country, target.Notes, target.IsComplete, time.Now()).Scan(&target.ID, &target.CreatedAt, &target.Version)
 		if err != nil {
-			return fmt.Errorf("could not create target: %v", err)
+			return fmt.Errorf("could not create target: %w", err)
+		}
+		if err = insertInitialNote(ctx, tx, target); err != nil {
+			return err
 		}
 	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit mission: %w", err)
+	}
 	return nil
 }
 
-func (repo *MissionRepository) ListMissions() ([]models.Mission, error) {
-	var missions []models.Mission
+func (repo *MissionRepository) ListMissions(ctx context.Context, filter models.MissionFilter) ([]models.Mission, int, error) {
+	var conditions []string
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.IsComplete != nil {
+		addCondition("is_complete = $%d", *filter.IsComplete)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.CatID != nil {
+		addCondition("cat_id = $%d", *filter.CatID)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("created_at <= $%d", *filter.CreatedTo)
+	}
+	if filter.Unassigned != nil {
+		if *filter.Unassigned {
+			conditions = append(conditions, "cat_id IS NULL")
+		} else {
+			conditions = append(conditions, "cat_id IS NOT NULL")
+		}
+	}
+	where := "TRUE"
+	if len(conditions) > 0 {
+		where = strings.Join(conditions, " AND ")
+	}
+
+	// Count all matching missions so clients can page through the result
+	var total int
+	err := repo.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM missions WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not count missions: %w", err)
+	}
 
-	// Query to get all missions
-	query := `SELECT id, cat_id, is_complete, created_at, updated_at, deleted_at FROM missions`
-	rows, err := repo.DB.Query(query)
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT id, COALESCE(cat_id, 0), COALESCE(previous_cat_id, 0), status, is_complete, version, completed_at, created_at, updated_at, deleted_at 
+						  FROM missions 
+						  WHERE %s 
+						  ORDER BY id 
+						  LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("could not get missions: %v", err)
+		return nil, 0, fmt.Errorf("could not get missions: %w", err)
 	}
 	defer rows.Close()
 
+	missions := []models.Mission{}
+	var missionIDs []int64
 	for rows.Next() {
 		var mission models.Mission
 		if err := rows.Scan(
 			&mission.ID,
 			&mission.CatID,
+			&mission.PreviousCatID,
+			&mission.Status,
 			&mission.IsComplete,
+			&mission.Version,
+			&mission.CompletedAt,
 			&mission.CreatedAt,
 			&mission.UpdatedAt,
 			&mission.DeletedAt); err != nil {
-			return nil, fmt.Errorf("could not scan mission: %v", err)
-		}
-
-		// Get all targets for this mission
-		targetQuery := `SELECT id, mission_id, name, country, notes, is_complete, created_at, updated_at, deleted_at FROM targets WHERE mission_id = $1`
-		targetRows, err := repo.DB.Query(targetQuery, mission.ID)
-		if err != nil {
-			return nil, fmt.Errorf("could not get targets: %v", err)
-		}
-		defer targetRows.Close()
-
-		var targets []models.Target
-		for targetRows.Next() {
-			var target models.Target
-			if err := targetRows.Scan(
-				&target.ID,
-				&target.MissionID,
-				&target.Name,
-				&target.Country,
-				&target.Notes,
-				&target.IsComplete,
-				&target.CreatedAt,
-				&target.UpdatedAt,
-				&target.DeletedAt); err != nil {
-				return nil, err
-			}
-			targets = append(targets, target)
+			return nil, 0, fmt.Errorf("could not scan mission: %w", err)
 		}
 
-		mission.Targets = targets
 		missions = append(missions, mission)
+		missionIDs = append(missionIDs, int64(mission.ID))
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return missions, nil
+	// Get the targets of the whole page in one query
+	targets, err := repo.targetsByMission(ctx, missionIDs, filter.IncludeDeleted)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range missions {
+		missions[i].Targets = targets[missions[i].ID]
+	}
+
+	return missions, total, nil
 }
 
-func (repo *MissionRepository) GetMissionByID(id uint) (*models.Mission, error) {
+// targetsByMission loads the targets of the given missions grouped by mission ID,
+// leaving out soft-deleted targets unless includeDeleted is set.
+func (repo *MissionRepository) targetsByMission(ctx context.Context, missionIDs []int64, includeDeleted bool) (map[uint][]models.Target, error) {
+	targets := make(map[uint][]models.Target)
+	if len(missionIDs) == 0 {
+		return targets, nil
+	}
+
+	query := `SELECT id, mission_id, name, country, COALESCE(notes, ''), is_complete, version, created_at, updated_at, deleted_at 
+			  FROM targets 
+			  WHERE mission_id = ANY($1) AND ($2 OR deleted_at IS NULL) 
+			  ORDER BY id`
+	rows, err := repo.DB.QueryContext(ctx, query, pq.Array(missionIDs), includeDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("could not get targets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var target models.Target
+		if err := rows.Scan(
+			&target.ID,
+			&target.MissionID,
+			&target.Name,
+			&target.Country,
+			&target.Notes,
+			&target.IsComplete,
+			&target.Version,
+			&target.CreatedAt,
+			&target.UpdatedAt,
+			&target.DeletedAt); err != nil {
+			return nil, fmt.Errorf("could not scan target: %w", err)
+		}
+		targets[target.MissionID] = append(targets[target.MissionID], target)
+	}
+
+	return targets, rows.Err()
+}
+
+func (repo *MissionRepository) GetMissionByID(ctx context.Context, id uint) (*models.Mission, error) {
 	var mission models.Mission
-	query := `SELECT id, cat_id, is_complete, created_at, updated_at FROM missions WHERE id = $1`
-	err := repo.DB.QueryRow(query, id).Scan(&mission.ID, &mission.CatID, &mission.IsComplete, &mission.CreatedAt, &mission.UpdatedAt)
+	query := `SELECT id, COALESCE(cat_id, 0), COALESCE(previous_cat_id, 0), status, is_complete, version, completed_at, created_at, updated_at 
+			  FROM missions 
+			  WHERE id = $1 AND deleted_at IS NULL`
+	err := repo.DB.QueryRowContext(ctx, query, id).Scan(&mission.ID, &mission.CatID, &mission.PreviousCatID, &mission.Status, &mission.IsComplete, &mission.Version, &mission.CompletedAt, &mission.CreatedAt, &mission.UpdatedAt)
 	if err != nil {
-		return nil, fmt.Errorf("could not get mission: %v", err)
+		return nil, notFoundOr(err, ErrMissionNotFound, "could not get mission")
 	}
 
 	// Get all targets for this mission
-	targetQuery := `SELECT id, mission_id, name, country, notes, is_complete, created_at, updated_at FROM targets WHERE mission_id = $1`
-	rows, err := repo.DB.Query(targetQuery, mission.ID)
+	targetQuery := `SELECT id, mission_id, name, country, COALESCE(notes, ''), is_complete, version, created_at, updated_at 
+					FROM targets 
+					WHERE mission_id = $1 AND deleted_at IS NULL 
+					ORDER BY id`
+	rows, err := repo.DB.QueryContext(ctx, targetQuery, mission.ID)
 	if err != nil {
-		return nil, fmt.Errorf("could not get targets: %v", err)
+		return nil, fmt.Errorf("could not get targets: %w", err)
 	}
 	defer rows.Close()
 
 	var targets []models.Target
 	for rows.Next() {
 		var target models.Target
-		if err := rows.Scan(&target.ID, &target.MissionID, &target.Name, &target.Country, &target.Notes, &target.IsComplete, &target.CreatedAt, &target.UpdatedAt); err != nil {
+		if err := rows.Scan(&target.ID, &target.MissionID, &target.Name, &target.Country, &target.Notes, &target.IsComplete, &target.Version, &target.CreatedAt, &target.UpdatedAt); err != nil {
 			return nil, err
 		}
 		targets = append(targets, target)
@@ -133,208 +249,491 @@ func (repo *MissionRepository) GetMissionByID(id uint) (*models.Mission, error)
 	return &mission, nil
 }
 
-func (repo *MissionRepository) UpdateMissionStatus(mission *models.Mission) error {
-	query := `UPDATE missions SET is_complete = $1, updated_at = $2 WHERE id = $3`
-	_, err := repo.DB.Exec(query, mission.IsComplete, time.Now(), mission.ID)
-	return err
+// SetMissionStatus moves a mission to the given status, keeping is_complete and completed_at in sync.
+// A non-zero version must match the mission's current version, and a mission with open targets cannot be completed.
+func (repo *MissionRepository) SetMissionStatus(ctx context.Context, id uint, version int, status models.MissionStatus) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current models.MissionStatus
+	var currentVersion int
+	err = tx.QueryRowContext(ctx, `SELECT status, version FROM missions WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id).Scan(&current, &currentVersion)
+	if err != nil {
+		return notFoundOr(err, ErrMissionNotFound, "error checking mission")
+	}
+	if err = CheckVersion(version, currentVersion); err != nil {
+		return err
+	}
+
+	// Count under the mission lock, which adding or completing a target also takes
+	if status == models.MissionCompleted {
+		var open int
+		query := `SELECT COUNT(*) FROM targets WHERE mission_id = $1 AND deleted_at IS NULL AND is_complete = FALSE`
+		if err = tx.QueryRowContext(ctx, query, id).Scan(&open); err != nil {
+			return fmt.Errorf("error counting open targets: %w", err)
+		}
+		if open > 0 {
+			return errOpenTargets(open)
+		}
+	}
+
+	var changes auditChanges
+	if err = setStatus(ctx, tx, id, current, status, &changes); err != nil {
+		return err
+	}
+	if err = changes.record(ctx, tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit mission status: %w", err)
+	}
+	return nil
 }
 
-func (repo *MissionRepository) DeleteMission(id uint) error {
+// setStatus moves a mission locked by the caller from one status to another.
+func setStatus(ctx context.Context, tx *sql.Tx, id uint, from, to models.MissionStatus, changes *auditChanges) error {
+	query := `UPDATE missions 
+			  SET status = $1, 
+			      is_complete = $2, 
+			      completed_at = CASE WHEN $2 THEN COALESCE(completed_at, $3) END, 
+			      updated_at = $3, 
+			      version = version + 1 
+			  WHERE id = $4`
+	_, err := tx.ExecContext(ctx, query, to, to == models.MissionCompleted, time.Now(), id)
+	if err != nil {
+		if isUniqueViolation(err, activeMissionIndex) {
+			return ErrCatHasActiveMission
+		}
+		return fmt.Errorf("could not update mission status: %w", err)
+	}
+	changes.add(models.AuditMission, id, id, "status", from, to)
+	return nil
+}
+
+func (repo *MissionRepository) DeleteMission(ctx context.Context, id uint, version int) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	var catID uint
+	var currentVersion int
 	// Get the cat ID associated with the mission
-	err := repo.DB.QueryRow(`SELECT cat_id FROM missions WHERE id = $1`, id).Scan(&catID)
-	if err != nil {
-		return fmt.Errorf("mission not found")
+	query := `SELECT COALESCE(cat_id, 0), version FROM missions WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, query, id).Scan(&catID, &currentVersion); err != nil {
+		return notFoundOr(err, ErrMissionNotFound, "error checking mission")
+	}
+	if err = CheckVersion(version, currentVersion); err != nil {
+		return err
 	}
 
 	// Prevent deleting a mission that has a cat assigned
 	if catID != 0 {
-		return fmt.Errorf("cannot delete mission assigned to a cat")
+		return ErrMissionAssigned
 	}
 
 	// Set deleted_at timestamp to logically delete the mission
-	query := `	UPDATE 
+	query = `	UPDATE 
 				    missions 
 				SET 
-				    deleted_at = $1 
+				    deleted_at = $1, version = version + 1 
 				WHERE 
 				    id = $2`
-	_, err = repo.DB.Exec(query, time.Now(), id)
-	return err
+	if _, err = tx.ExecContext(ctx, query, time.Now(), id); err != nil {
+		return fmt.Errorf("could not delete mission: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit mission deletion: %w", err)
+	}
+	return nil
 }
 
-func (repo *MissionRepository) AddTargetToMission(missionID uint, target *models.Target) error {
-	// Check if the mission exists and if it is completed
-	var isComplete bool
-	err := repo.DB.QueryRow(`SELECT is_complete FROM missions WHERE id = $1`, missionID).Scan(&isComplete)
+func (repo *MissionRepository) AddTargetToMission(ctx context.Context, missionID uint, target *models.Target) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("mission not found")
-		}
-		return fmt.Errorf("error checking mission: %v", err)
+		return fmt.Errorf("could not start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Prevent adding targets to completed missions
-	if isComplete {
-		return fmt.Errorf("cannot add target to a completed mission")
+	// Check if the mission exists and if it is closed
+	var status models.MissionStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM missions WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, missionID).Scan(&status)
+	if err != nil {
+		return notFoundOr(err, ErrMissionNotFound, "error checking mission")
+	}
+
+	// Prevent adding targets to completed or aborted missions
+	if status.Closed() {
+		return errMissionClosed(status)
 	}
 
-	// Check if there are already 3 targets in the mission
+	// Check if the mission already has the maximum number of targets
 	var targetCount int
-	err = repo.DB.QueryRow(`SELECT COUNT(*) FROM targets WHERE mission_id = $1`, missionID).Scan(&targetCount)
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM targets WHERE mission_id = $1 AND deleted_at IS NULL`, missionID).Scan(&targetCount)
 	if err != nil {
-		return fmt.Errorf("error counting targets: %v", err)
+		return fmt.Errorf("error counting targets: %w", err)
 	}
 
-	if targetCount >= 3 {
-		return fmt.Errorf("cannot add more than 3 targets to a mission")
+	if targetCount >= models.MaxTargetsPerMission {
+		return ErrTooManyTargets
 	}
 
 	// Insert a new target for the mission
 	query := `INSERT INTO targets (mission_id, name, country, notes, is_complete, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
-	err = repo.DB.QueryRow(query, missionID, target.Name, target.Country, target.Notes, target.IsComplete, time.Now(), time.Now()).Scan(&target.ID)
+			  VALUES ($1, $2, $3, $4, $5, $6, $6) RETURNING id, created_at, updated_at, version`
+	err = tx.QueryRowContext(ctx, query, missionID, target.Name, target.Country, target.Notes, target.IsComplete, time.Now()).Scan(&target.ID, &target.CreatedAt, &target.UpdatedAt, &target.Version)
 	if err != nil {
-		return fmt.Errorf("error inserting target: %v", err)
+		return fmt.Errorf("error inserting target: %w", err)
 	}
+	target.MissionID = missionID
 
+	if err = insertInitialNote(ctx, tx, target); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit target: %w", err)
+	}
 	return nil
 }
 
-func (repo *MissionRepository) AssignCatToMission(missionID, catID uint) error {
+func (repo *MissionRepository) AssignCatToMission(ctx context.Context, missionID, catID uint, version int) (int, error) {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Check if the mission exists
-	var existingMissionID uint
-	err := repo.DB.QueryRow(`SELECT id FROM missions WHERE id = $1 AND is_complete = FALSE`, missionID).Scan(&existingMissionID)
+	var previousCatID uint
+	var status models.MissionStatus
+	var currentVersion int
+	query := `SELECT COALESCE(cat_id, 0), status, version FROM missions WHERE id = $1 AND ` + activeMissionCondition + ` FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, query, missionID).Scan(&previousCatID, &status, &currentVersion); err != nil {
+		return 0, notFoundOr(err, ErrMissionNotFound, "error checking mission")
+	}
+	if err = CheckVersion(version, currentVersion); err != nil {
+		return 0, err
+	}
+
+	// Check if the cat exists
+	var existingCatID uint
+	err = tx.QueryRowContext(ctx, `SELECT id FROM cats WHERE id = $1 AND deleted_at IS NULL`, catID).Scan(&existingCatID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("mission not found")
-		}
-		return fmt.Errorf("error checking mission: %v", err)
+		return 0, notFoundOr(err, ErrCatNotFound, "error checking cat")
 	}
 
 	// Check if the cat already has an active mission
 	var activeMissionCount int
-	err = repo.DB.QueryRow(`SELECT COUNT(*) FROM missions WHERE cat_id = $1 AND is_complete = FALSE`, catID).Scan(&activeMissionCount)
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM missions WHERE cat_id = $1 AND `+activeMissionCondition, catID).Scan(&activeMissionCount)
 	if err != nil {
-		return fmt.Errorf("error checking active mission: %v", err)
+		return 0, fmt.Errorf("error checking active mission: %w", err)
 	}
 
 	// Prevent assigning a cat that already has an active mission
 	if activeMissionCount > 0 {
-		return fmt.Errorf("this cat already has an active mission")
+		return 0, ErrCatHasActiveMission
+	}
+
+	// Assign the cat to the mission, remembering the cat it is taken from
+	query = `UPDATE missions 
+			 SET previous_cat_id = COALESCE(cat_id, previous_cat_id), 
+			     cat_id = $1, 
+			     status = CASE WHEN status = 'draft' THEN 'assigned' ELSE status END, 
+			     updated_at = $2, 
+			     version = version + 1 
+			 WHERE id = $3 
+			 RETURNING status, version`
+	var newStatus models.MissionStatus
+	var newVersion int
+	err = tx.QueryRowContext(ctx, query, catID, time.Now(), missionID).Scan(&newStatus, &newVersion)
+	if err != nil {
+		if isUniqueViolation(err, activeMissionIndex) {
+			return 0, ErrCatHasActiveMission
+		}
+		return 0, fmt.Errorf("could not assign cat to mission: %w", err)
 	}
 
-	// Assign the cat to the mission
-	query := `UPDATE missions SET cat_id = $1 WHERE id = $2`
-	_, err = repo.DB.Exec(query, catID, missionID)
+	var changes auditChanges
+	changes.missionCat(missionID, previousCatID, catID)
+	changes.add(models.AuditMission, missionID, missionID, "status", status, newStatus)
+	if err = changes.record(ctx, tx); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("could not commit assignment: %w", err)
+	}
+	return newVersion, nil
+}
+
+// UnassignCatFromMission returns an active mission to the unassigned pool as a draft.
+func (repo *MissionRepository) UnassignCatFromMission(ctx context.Context, missionID uint, version int) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("could not assign cat to mission: %v", err)
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var catID uint
+	var status models.MissionStatus
+	var currentVersion int
+	query := `SELECT COALESCE(cat_id, 0), status, version FROM missions WHERE id = $1 AND ` + activeMissionCondition + ` FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, query, missionID).Scan(&catID, &status, &currentVersion); err != nil {
+		return notFoundOr(err, ErrMissionNotFound, "error checking mission")
+	}
+	if err = CheckVersion(version, currentVersion); err != nil {
+		return err
+	}
+	if catID == 0 {
+		return ErrMissionUnassigned
 	}
 
+	if err = unassignMission(ctx, tx, missionID, catID, status); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit unassignment: %w", err)
+	}
 	return nil
 }
 
-func (repo *MissionRepository) UpdateTargetStatus(target *models.Target) error {
-	// Check if the target is complete
-	var isTargetComplete bool
-	query := `SELECT is_complete FROM targets WHERE id = $1`
-	err := repo.DB.QueryRow(query, target.ID).Scan(&isTargetComplete)
+func (repo *MissionRepository) UpdateTargetStatus(ctx context.Context, target *models.Target) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("could not find target: %v", err)
+		return fmt.Errorf("could not start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Prevent updating a completed target
-	if isTargetComplete {
-		return fmt.Errorf("cannot update: target is complete")
+	// Lock the mission before the target, in the order the other mission writes take them
+	var status models.MissionStatus
+	query := `SELECT m.status FROM missions m JOIN targets t ON t.mission_id = m.id WHERE t.id = $1 FOR UPDATE OF m`
+	if err = tx.QueryRowContext(ctx, query, target.ID).Scan(&status); err != nil {
+		return notFoundOr(err, ErrTargetNotFound, "could not find target")
 	}
 
-	// Check if the mission of the target is complete
-	var missionID uint
-	query = `SELECT mission_id FROM targets WHERE id = $1`
-	err = repo.DB.QueryRow(query, target.ID).Scan(&missionID)
+	current, err := openTarget(ctx, tx, uint(target.ID), target.Version)
 	if err != nil {
-		return fmt.Errorf("could not find mission for target: %v", err)
+		return err
+	}
+	// A draft has no cat to do the work; its targets wait until one is assigned
+	if status == models.MissionDraft {
+		return ErrMissionUnassigned
 	}
+	target.MissionID = current.MissionID
 
-	var isMissionComplete bool
-	query = `SELECT is_complete FROM missions WHERE id = $1`
-	err = repo.DB.QueryRow(query, missionID).Scan(&isMissionComplete)
-	if err != nil {
-		return fmt.Errorf("could not find mission: %v", err)
+	// Update target status to complete
+	query = `UPDATE targets SET is_complete = $1, updated_at = $2, version = version + 1 WHERE id = $3 RETURNING version`
+	if err = tx.QueryRowContext(ctx, query, target.IsComplete, time.Now(), target.ID).Scan(&target.Version); err != nil {
+		return fmt.Errorf("could not update target status: %w", err)
 	}
 
-	// Prevent updating if the mission is complete
-	if isMissionComplete {
-		return fmt.Errorf("cannot update: mission is complete")
+	var changes auditChanges
+	changes.add(models.AuditTarget, uint(target.ID), current.MissionID, "is_complete", current.IsComplete, target.IsComplete)
+	if target.IsComplete {
+		if err = advanceMission(ctx, tx, current.MissionID, status, &changes); err != nil {
+			return err
+		}
 	}
+	if err = changes.record(ctx, tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit target status: %w", err)
+	}
+	return nil
+}
 
-	// Update target status to complete
-	query = `UPDATE targets SET is_complete = $1 WHERE id = $2`
-	_, err = repo.DB.Exec(query, target.IsComplete, target.ID)
-	return err
+// advanceMission starts a mission with its first completed target and
+// completes it with the last one. The caller holds the mission lock.
+func advanceMission(ctx context.Context, tx *sql.Tx, missionID uint, status models.MissionStatus, changes *auditChanges) error {
+	var open int
+	query := `SELECT COUNT(*) FROM targets WHERE mission_id = $1 AND deleted_at IS NULL AND is_complete = FALSE`
+	if err := tx.QueryRowContext(ctx, query, missionID).Scan(&open); err != nil {
+		return fmt.Errorf("error counting open targets: %w", err)
+	}
+
+	next := models.MissionInProgress
+	if open == 0 {
+		next = models.MissionCompleted
+	}
+	if !status.CanTransitionTo(next) {
+		return nil
+	}
+	return setStatus(ctx, tx, missionID, status, next, changes)
 }
 
-func (repo *MissionRepository) UpdateTargetNotes(target *models.Target) error {
-	// Check if the target is complete
-	var isTargetComplete bool
-	query := `SELECT is_complete FROM targets WHERE id = $1`
-	err := repo.DB.QueryRow(query, target.ID).Scan(&isTargetComplete)
+// openTarget locks a target that may still be changed: neither the target nor
+// its mission is deleted or complete, and a non-zero version still matches.
+// It returns the target's current state.
+func openTarget(ctx context.Context, tx *sql.Tx, id uint, version int) (*models.Target, error) {
+	var target models.Target
+	var missionStatus *models.MissionStatus
+	query := `SELECT t.id, t.mission_id, COALESCE(t.notes, ''), t.is_complete, t.version, m.status 
+			  FROM targets t 
+			  LEFT JOIN missions m ON m.id = t.mission_id AND m.deleted_at IS NULL 
+			  WHERE t.id = $1 AND t.deleted_at IS NULL 
+			  FOR UPDATE OF t`
+	err := tx.QueryRowContext(ctx, query, id).Scan(&target.ID, &target.MissionID, &target.Notes, &target.IsComplete, &target.Version, &missionStatus)
 	if err != nil {
-		return fmt.Errorf("could not find target: %v", err)
+		return nil, notFoundOr(err, ErrTargetNotFound, "could not find target")
+	}
+	if err = CheckVersion(version, target.Version); err != nil {
+		return nil, err
 	}
 
-	// Prevent updating notes of a completed target
-	if isTargetComplete {
-		return fmt.Errorf("cannot update notes: target is complete")
+	// Prevent changing a completed target
+	if target.IsComplete {
+		return nil, ErrTargetComplete
+	}
+	if missionStatus == nil {
+		return nil, ErrMissionNotFound
 	}
 
-	// Check if the mission of the target is complete
-	var missionID uint
-	query = `SELECT mission_id FROM targets WHERE id = $1`
-	err = repo.DB.QueryRow(query, target.ID).Scan(&missionID)
-	if err != nil {
-		return fmt.Errorf("could not find mission for target: %v", err)
+	// Prevent changing targets of completed or aborted missions
+	if missionStatus.Closed() {
+		return nil, errMissionClosed(*missionStatus)
 	}
+	return &target, nil
+}
 
-	var isMissionComplete bool
-	query = `SELECT is_complete FROM missions WHERE id = $1`
-	err = repo.DB.QueryRow(query, missionID).Scan(&isMissionComplete)
+func (repo *MissionRepository) DeleteTarget(ctx context.Context, id uint, version int) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("could not find mission: %v", err)
+		return fmt.Errorf("could not start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Prevent updating notes if the mission is complete
-	if isMissionComplete {
-		return fmt.Errorf("cannot update notes: mission is complete")
+	// Lock the mission before the target, so the target count cannot change meanwhile
+	var missionID uint
+	query := `SELECT m.id FROM missions m JOIN targets t ON t.mission_id = m.id WHERE t.id = $1 FOR UPDATE OF m`
+	if err = tx.QueryRowContext(ctx, query, id).Scan(&missionID); err != nil {
+		return notFoundOr(err, ErrTargetNotFound, "could not find target")
 	}
 
-	// Update target notes
-	query = `UPDATE targets SET notes = $1 WHERE id = $2`
-	_, err = repo.DB.Exec(query, target.Notes, target.ID)
-	return err
-}
-
-func (repo *MissionRepository) DeleteTarget(id uint) error {
 	// Check if the target is complete
 	var isComplete bool
-	query := `SELECT is_complete FROM targets WHERE id = $1`
-	err := repo.DB.QueryRow(query, id).Scan(&isComplete)
-	if err != nil {
-		return fmt.Errorf("target not found")
+	var currentVersion int
+	query = `SELECT is_complete, version FROM targets WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, query, id).Scan(&isComplete, &currentVersion); err != nil {
+		return notFoundOr(err, ErrTargetNotFound, "could not find target")
+	}
+	if err = CheckVersion(version, currentVersion); err != nil {
+		return err
 	}
 
 	// Prevent deleting a completed target
 	if isComplete {
-		return fmt.Errorf("cannot delete a completed target")
+		return ErrTargetComplete
+	}
+
+	// Prevent deleting the last target of a mission
+	var count int
+	query = `SELECT COUNT(*) FROM targets WHERE mission_id = $1 AND deleted_at IS NULL`
+	if err = tx.QueryRowContext(ctx, query, missionID).Scan(&count); err != nil {
+		return fmt.Errorf("error counting targets: %w", err)
+	}
+	if count <= models.MinTargetsPerMission {
+		return ErrTooFewTargets
 	}
 
 	// Set deleted_at timestamp to logically delete the target
 	query = `	UPDATE 
 				    targets 
 				SET 
-				    deleted_at = $1 
+				    deleted_at = $1, version = version + 1 
 				WHERE 
 				    id = $2`
-	_, err = repo.DB.Exec(query, time.Now(), id)
-	return err
+	if _, err = tx.ExecContext(ctx, query, time.Now(), id); err != nil {
+		return fmt.Errorf("could not delete target: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit target deletion: %w", err)
+	}
+	return nil
+}
+
+func (repo *MissionRepository) GetTargetByID(ctx context.Context, id uint) (*models.Target, error) {
+	var target models.Target
+	query := `SELECT id, mission_id, name, country, COALESCE(notes, ''), is_complete, version, created_at, updated_at 
+			  FROM targets 
+			  WHERE id = $1 AND deleted_at IS NULL`
+	err := repo.DB.QueryRowContext(ctx, query, id).Scan(&target.ID, &target.MissionID, &target.Name, &target.Country, &target.Notes, &target.IsComplete, &target.Version, &target.CreatedAt, &target.UpdatedAt)
+	if err != nil {
+		return nil, notFoundOr(err, ErrTargetNotFound, "could not get target")
+	}
+	return &target, nil
+}
+
+// RestoreMission clears the deleted_at of a soft-deleted mission. An active
+// mission is only restored while its cat has no other active mission.
+func (repo *MissionRepository) RestoreMission(ctx context.Context, id uint) error {
+	var deletedAt *time.Time
+	err := repo.DB.QueryRowContext(ctx, `SELECT deleted_at FROM missions WHERE id = $1`, id).Scan(&deletedAt)
+	if err != nil {
+		return notFoundOr(err, ErrMissionNotFound, "could not get mission")
+	}
+	if deletedAt == nil {
+		return errNotDeleted("mission")
+	}
+
+	_, err = repo.DB.ExecContext(ctx, `UPDATE missions SET deleted_at = NULL, updated_at = $1, version = version + 1 WHERE id = $2`, time.Now(), id)
+	if err != nil {
+		if isUniqueViolation(err, activeMissionIndex) {
+			return ErrCatHasActiveMission
+		}
+		return fmt.Errorf("could not restore mission: %w", err)
+	}
+	return nil
+}
+
+// RestoreTarget clears the deleted_at of a soft-deleted target, provided its
+// mission is live, still open and below the target cap.
+func (repo *MissionRepository) RestoreTarget(ctx context.Context, id uint) error {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var missionID uint
+	var deletedAt *time.Time
+	err = tx.QueryRowContext(ctx, `SELECT mission_id, deleted_at FROM targets WHERE id = $1`, id).Scan(&missionID, &deletedAt)
+	if err != nil {
+		return notFoundOr(err, ErrTargetNotFound, "could not get target")
+	}
+	if deletedAt == nil {
+		return errNotDeleted("target")
+	}
+
+	// Lock the mission so the target cap cannot be exceeded concurrently
+	var status models.MissionStatus
+	err = tx.QueryRowContext(ctx, `SELECT status FROM missions WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, missionID).Scan(&status)
+	if err != nil {
+		return notFoundOr(err, ErrMissionNotFound, "could not get mission")
+	}
+	if status.Closed() {
+		return errMissionClosed(status)
+	}
+
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM targets WHERE mission_id = $1 AND deleted_at IS NULL`, missionID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("error counting targets: %w", err)
+	}
+	if count >= models.MaxTargetsPerMission {
+		return ErrTooManyTargets
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE targets SET deleted_at = NULL, updated_at = $1, version = version + 1 WHERE id = $2`, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("could not restore target: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit target restore: %w", err)
+	}
+	return nil
 }
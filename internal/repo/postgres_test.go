@@ -0,0 +1,201 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"devTodTestTask/internal/models"
+)
+
+// openTestDB applies every up migration to a fresh schema of the database in
+// TEST_DATABASE_URL and returns a connection using that schema. The test is
+// skipped when the variable is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db := openEmptyDB(t)
+	migrate(t, db, "", "~")
+	return db
+}
+
+// openEmptyDB returns a connection using a fresh schema with no migrations
+// applied. The test is skipped when TEST_DATABASE_URL is not set.
+func openEmptyDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+	})
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("TEST_DATABASE_URL must be a URL: %v", err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	db, err := sql.Open("postgres", u.String())
+	if err != nil {
+		t.Fatalf("open schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// migrate applies the up migrations whose file names sort in [from, to).
+func migrate(t *testing.T, db *sql.DB, from, to string) {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.up.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if name := filepath.Base(file); name < from || name >= to {
+			continue
+		}
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		if _, err := db.Exec(string(migration)); err != nil {
+			t.Fatalf("apply %s: %v", filepath.Base(file), err)
+		}
+	}
+}
+
+// insertID runs an INSERT ... RETURNING id and returns the new ID.
+func insertID(t *testing.T, db *sql.DB, query string, args ...interface{}) uint {
+	t.Helper()
+	var id uint
+	if err := db.QueryRow(query, args...).Scan(&id); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	return id
+}
+
+func TestPostgresScansNullTimestamps(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	// Rows written before the timestamps were filled in have NULLs everywhere
+	catID := insertID(t, db, `INSERT INTO cats (name, experience, breed, salary, created_at, updated_at, deleted_at)
+		VALUES ('Tom', 3, 'Siamese', 1000, NULL, NULL, NULL) RETURNING id`)
+	missionID := insertID(t, db, `INSERT INTO missions (cat_id, is_complete, completed_at, created_at, updated_at, deleted_at)
+		VALUES ($1, FALSE, NULL, NULL, NULL, NULL) RETURNING id`, catID)
+	insertID(t, db, `INSERT INTO targets (mission_id, name, country, notes, created_at, updated_at, deleted_at)
+		VALUES ($1, 'Target', 'UA', NULL, NULL, NULL, NULL) RETURNING id`, missionID)
+	unassignedID := insertID(t, db, `INSERT INTO missions (cat_id, status, is_complete, completed_at, created_at, updated_at, deleted_at)
+		VALUES (NULL, 'draft', FALSE, NULL, NULL, NULL, NULL) RETURNING id`)
+
+	cats := &CatRepository{DB: db}
+	missions := &MissionRepository{DB: db}
+
+	cat, err := cats.GetCatByID(ctx, catID)
+	if err != nil {
+		t.Fatalf("GetCatByID: %v", err)
+	}
+	if cat.CreatedAt != nil || cat.UpdatedAt != nil {
+		t.Errorf("cat timestamps = %v, %v, want nil", cat.CreatedAt, cat.UpdatedAt)
+	}
+
+	list, total, err := cats.ListCats(ctx, models.CatFilter{Page: models.Page{Limit: 10}})
+	if err != nil {
+		t.Fatalf("ListCats: %v", err)
+	}
+	if total != 1 || len(list) != 1 || list[0].DeletedAt != nil {
+		t.Errorf("ListCats = %+v (total %d), want the one cat", list, total)
+	}
+
+	mission, err := missions.GetMissionByID(ctx, missionID)
+	if err != nil {
+		t.Fatalf("GetMissionByID: %v", err)
+	}
+	if mission.CompletedAt != nil || mission.UpdatedAt != nil {
+		t.Errorf("mission timestamps = %v, %v, want nil", mission.CompletedAt, mission.UpdatedAt)
+	}
+	if len(mission.Targets) != 1 || mission.Targets[0].UpdatedAt != nil || mission.Targets[0].Notes != "" {
+		t.Errorf("mission targets = %+v, want one target without notes or timestamps", mission.Targets)
+	}
+
+	unassigned, err := missions.GetMissionByID(ctx, unassignedID)
+	if err != nil {
+		t.Fatalf("GetMissionByID(unassigned): %v", err)
+	}
+	if unassigned.CatID != 0 {
+		t.Errorf("unassigned mission cat = %d, want 0", unassigned.CatID)
+	}
+
+	all, total, err := missions.ListMissions(ctx, models.MissionFilter{Page: models.Page{Limit: 10}})
+	if err != nil {
+		t.Fatalf("ListMissions: %v", err)
+	}
+	if total != 2 || len(all) != 2 {
+		t.Fatalf("ListMissions returned %d of %d missions, want 2", len(all), total)
+	}
+	if all[0].DeletedAt != nil || len(all[0].Targets) != 1 || all[0].Targets[0].DeletedAt != nil {
+		t.Errorf("listed mission = %+v, want one target and no deleted_at", all[0])
+	}
+}
+
+func TestPostgresDraftTargetsWaitForCat(t *testing.T) {
+	testDraftTargetsWaitForCat(t, &MissionRepository{DB: openTestDB(t)})
+}
+
+func TestPostgresDeleteLastTarget(t *testing.T) {
+	testDeleteLastTarget(t, &MissionRepository{DB: openTestDB(t)})
+}
+
+func TestPostgresBreedFilter(t *testing.T) {
+	testBreedFilter(t, &CatRepository{DB: openTestDB(t)})
+}
+
+func TestPostgresMigrationKeepsDuplicateMissionsOpen(t *testing.T) {
+	db := openEmptyDB(t)
+	migrate(t, db, "", "0004")
+	catID := insertID(t, db, `INSERT INTO cats (name, experience, breed, salary) VALUES ('Tom', 1, 'Siamese', 100) RETURNING id`)
+	older := insertID(t, db, `INSERT INTO missions (cat_id) VALUES ($1) RETURNING id`, catID)
+	newer := insertID(t, db, `INSERT INTO missions (cat_id) VALUES ($1) RETURNING id`, catID)
+	migrate(t, db, "0004", "~")
+
+	var status string
+	var completedAt, deletedAt *time.Time
+	err := db.QueryRow(`SELECT status, completed_at, deleted_at FROM missions WHERE id = $1`, older).Scan(&status, &completedAt, &deletedAt)
+	if err != nil {
+		t.Fatalf("select older mission: %v", err)
+	}
+	if status == string(models.MissionCompleted) || completedAt != nil || deletedAt == nil {
+		t.Errorf("older duplicate: status %s, completed_at %v, deleted_at %v; want it soft-deleted, not completed", status, completedAt, deletedAt)
+	}
+
+	err = db.QueryRow(`SELECT status, deleted_at FROM missions WHERE id = $1`, newer).Scan(&status, &deletedAt)
+	if err != nil {
+		t.Fatalf("select newer mission: %v", err)
+	}
+	if status == string(models.MissionCompleted) || deletedAt != nil {
+		t.Errorf("newer mission: status %s, deleted_at %v; want it live and open", status, deletedAt)
+	}
+}
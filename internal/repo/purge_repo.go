@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PurgeResult counts the rows removed by a purge.
+type PurgeResult struct {
+	Cats     int64
+	Missions int64
+	Targets  int64
+	// IdempotencyKeys is filled in by the purge job from the IdempotencyStore.
+	IdempotencyKeys int64
+}
+
+type PurgeRepository struct {
+	DB *sql.DB
+}
+
+// PurgeDeleted hard-deletes rows soft-deleted before the cutoff. Targets of
+// purged missions go with them; cats are kept while any mission or note references them.
+func (repo *PurgeRepository) PurgeDeleted(ctx context.Context, before time.Time) (PurgeResult, error) {
+	var result PurgeResult
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	steps := []struct {
+		query string
+		count *int64
+	}{
+		{`DELETE FROM targets WHERE deleted_at < $1`, &result.Targets},
+		{`DELETE FROM missions WHERE deleted_at < $1`, &result.Missions},
+		{`DELETE FROM cats WHERE deleted_at < $1 
+		    AND NOT EXISTS (SELECT 1 FROM missions WHERE missions.cat_id = cats.id) 
+		    AND NOT EXISTS (SELECT 1 FROM target_notes WHERE target_notes.author_cat_id = cats.id)`, &result.Cats},
+	}
+	for _, step := range steps {
+		res, err := tx.ExecContext(ctx, step.query, before)
+		if err != nil {
+			return PurgeResult{}, fmt.Errorf("could not purge deleted rows: %w", err)
+		}
+		if *step.count, err = res.RowsAffected(); err != nil {
+			return PurgeResult{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return PurgeResult{}, fmt.Errorf("could not commit purge: %w", err)
+	}
+	return result, nil
+}
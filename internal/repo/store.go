@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"time"
+)
+
+// CatStore persists cats. CatRepository is the Postgres implementation and
+// MemoryStore the in-memory one used to run the services without a database.
+// UpdateCat and DeleteCat check the cat's Version like the MissionStore mutations.
+type CatStore interface {
+	CreateCat(ctx context.Context, cat *models.Cat) error
+	ListCats(ctx context.Context, filter models.CatFilter) ([]models.Cat, int, error)
+	GetCatByID(ctx context.Context, id uint) (*models.Cat, error)
+	UpdateCat(ctx context.Context, cat *models.Cat) error
+	DeleteCat(ctx context.Context, cat *models.Cat, retirement models.CatRetirement) error
+	RestoreCat(ctx context.Context, id uint) error
+}
+
+// MissionStore persists missions and their targets. MissionRepository is the
+// Postgres implementation and MemoryStore the in-memory one.
+//
+// Mutations taking a version, or a model carrying one, fail with a 412 error
+// unless it matches the row's current version; 0 skips the check.
+type MissionStore interface {
+	CreateMission(ctx context.Context, mission *models.Mission) error
+	ListMissions(ctx context.Context, filter models.MissionFilter) ([]models.Mission, int, error)
+	GetMissionByID(ctx context.Context, id uint) (*models.Mission, error)
+	SetMissionStatus(ctx context.Context, id uint, version int, status models.MissionStatus) error
+	DeleteMission(ctx context.Context, id uint, version int) error
+	RestoreMission(ctx context.Context, id uint) error
+	AddTargetToMission(ctx context.Context, missionID uint, target *models.Target) error
+	// AssignCatToMission returns the new version of the mission.
+	AssignCatToMission(ctx context.Context, missionID, catID uint, version int) (int, error)
+	UnassignCatFromMission(ctx context.Context, missionID uint, version int) error
+	UpdateTargetStatus(ctx context.Context, target *models.Target) error
+	// AddTargetNote returns the new version of the target.
+	AddTargetNote(ctx context.Context, note *models.TargetNote, version int) (int, error)
+	ListTargetNotes(ctx context.Context, targetID uint) ([]models.TargetNote, error)
+	GetTargetNote(ctx context.Context, targetID uint, revision int) (*models.TargetNote, error)
+	GetTargetByID(ctx context.Context, id uint) (*models.Target, error)
+	DeleteTarget(ctx context.Context, id uint, version int) error
+	RestoreTarget(ctx context.Context, id uint) error
+}
+
+// AuditStore reads the append-only audit history written by the other stores.
+// AuditRepository is the Postgres implementation and MemoryStore the in-memory one.
+type AuditStore interface {
+	MissionHistory(ctx context.Context, missionID uint) ([]models.AuditEvent, error)
+	CatHistory(ctx context.Context, catID uint) ([]models.AuditEvent, error)
+}
+
+// PurgeStore permanently removes soft-deleted rows. PurgeRepository is the
+// Postgres implementation and MemoryStore the in-memory one.
+type PurgeStore interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (PurgeResult, error)
+}
+
+// IdempotencyStore keeps the first response to each Idempotency-Key.
+// IdempotencyRepository is the Postgres implementation and MemoryStore the in-memory one.
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, staleBefore time.Time) (*models.IdempotencyRecord, error)
+	SaveIdempotentResponse(ctx context.Context, record *models.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error
+	PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+// APIKeyStore keeps the hashes of issued API keys. APIKeyRepository is the
+// Postgres implementation and MemoryStore the in-memory one.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	UseAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+}
+
+var (
+	_ CatStore     = (*CatRepository)(nil)
+	_ MissionStore = (*MissionRepository)(nil)
+	_ CatStore     = (*MemoryStore)(nil)
+	_ MissionStore = (*MemoryStore)(nil)
+	_ PurgeStore   = (*PurgeRepository)(nil)
+	_ PurgeStore   = (*MemoryStore)(nil)
+	_ AuditStore   = (*AuditRepository)(nil)
+	_ AuditStore   = (*MemoryStore)(nil)
+
+	_ IdempotencyStore = (*IdempotencyRepository)(nil)
+	_ IdempotencyStore = (*MemoryStore)(nil)
+	_ APIKeyStore      = (*APIKeyRepository)(nil)
+	_ APIKeyStore      = (*MemoryStore)(nil)
+)
@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"testing"
+	"time"
+)
+
+// testDraftTargetsWaitForCat checks that a store refuses to complete the
+// targets of a mission that has no cat yet.
+func testDraftTargetsWaitForCat(t *testing.T, missions MissionStore) {
+	ctx := context.Background()
+	draft := &models.Mission{Status: models.MissionDraft, Targets: []models.Target{{Name: "Target", Country: "UA"}}}
+	if err := missions.CreateMission(ctx, draft); err != nil {
+		t.Fatalf("CreateMission: %v", err)
+	}
+
+	err := missions.UpdateTargetStatus(ctx, &models.Target{ID: draft.Targets[0].ID, IsComplete: true})
+	if err != ErrMissionUnassigned {
+		t.Fatalf("UpdateTargetStatus on a draft = %v, want %v", err, ErrMissionUnassigned)
+	}
+
+	target, err := missions.GetTargetByID(ctx, uint(draft.Targets[0].ID))
+	if err != nil {
+		t.Fatalf("GetTargetByID: %v", err)
+	}
+	if target.IsComplete {
+		t.Error("target of a draft was completed")
+	}
+}
+
+func TestMemoryDraftTargetsWaitForCat(t *testing.T) {
+	testDraftTargetsWaitForCat(t, NewMemoryStore())
+}
+
+// testDeleteLastTarget checks that a store keeps the last target of a mission.
+func testDeleteLastTarget(t *testing.T, missions MissionStore) {
+	ctx := context.Background()
+	mission := &models.Mission{Status: models.MissionDraft, Targets: []models.Target{{Name: "First", Country: "UA"}, {Name: "Second", Country: "UA"}}}
+	if err := missions.CreateMission(ctx, mission); err != nil {
+		t.Fatalf("CreateMission: %v", err)
+	}
+
+	if err := missions.DeleteTarget(ctx, uint(mission.Targets[0].ID), 0); err != nil {
+		t.Fatalf("DeleteTarget: %v", err)
+	}
+	if err := missions.DeleteTarget(ctx, uint(mission.Targets[1].ID), 0); err != ErrTooFewTargets {
+		t.Fatalf("DeleteTarget of the last target = %v, want %v", err, ErrTooFewTargets)
+	}
+	if _, err := missions.GetTargetByID(ctx, uint(mission.Targets[1].ID)); err != nil {
+		t.Errorf("last target is gone: %v", err)
+	}
+}
+
+func TestMemoryDeleteLastTarget(t *testing.T) {
+	testDeleteLastTarget(t, NewMemoryStore())
+}
+
+// testBreedFilter checks that a store matches the breed filter exactly,
+// ignoring case, and does not treat LIKE wildcards in it as patterns.
+func testBreedFilter(t *testing.T, cats CatStore) {
+	ctx := context.Background()
+	for _, breed := range []string{"Siamese", "Sphynx"} {
+		if err := cats.CreateCat(ctx, &models.Cat{Name: breed, Experience: 1, Breed: breed, Salary: 100}); err != nil {
+			t.Fatalf("CreateCat: %v", err)
+		}
+	}
+
+	for breed, want := range map[string]int{"siamese": 1, " SPHYNX ": 1, "%": 0, "S%": 0, "S_amese": 0, "Siam": 0} {
+		_, total, err := cats.ListCats(ctx, models.CatFilter{Breed: breed})
+		if err != nil {
+			t.Fatalf("ListCats(%q): %v", breed, err)
+		}
+		if total != want {
+			t.Errorf("ListCats(%q) matched %d cats, want %d", breed, total, want)
+		}
+	}
+}
+
+func TestMemoryBreedFilter(t *testing.T) {
+	testBreedFilter(t, NewMemoryStore())
+}
+
+func TestIdempotencyLease(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	expired := time.Now().Add(-time.Hour)
+	newRecord := func() *models.IdempotencyRecord {
+		return &models.IdempotencyRecord{Subject: "client", Key: "k1", RequestHash: "hash"}
+	}
+
+	crashed := newRecord()
+	if existing, err := store.ReserveIdempotencyKey(ctx, crashed, expired, expired); err != nil || existing != nil {
+		t.Fatalf("first reservation = %v, %v", existing, err)
+	}
+	if existing, _ := store.ReserveIdempotencyKey(ctx, newRecord(), expired, expired); existing == nil {
+		t.Fatal("a live reservation was taken over")
+	}
+
+	// Once the lease is over the reservation is taken over
+	retry := newRecord()
+	stale := time.Now().Add(time.Second)
+	if existing, err := store.ReserveIdempotencyKey(ctx, retry, expired, stale); err != nil || existing != nil {
+		t.Fatalf("takeover = %v, %v, want the key reserved", existing, err)
+	}
+
+	// The crashed request can no longer release or answer for the key
+	if err := store.ReleaseIdempotencyKey(ctx, crashed); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey: %v", err)
+	}
+	crashed.StatusCode = 500
+	if err := store.SaveIdempotentResponse(ctx, crashed); err != nil {
+		t.Fatalf("SaveIdempotentResponse: %v", err)
+	}
+	retry.StatusCode = 201
+	if err := store.SaveIdempotentResponse(ctx, retry); err != nil {
+		t.Fatalf("SaveIdempotentResponse: %v", err)
+	}
+
+	// A stored response is replayed however old the reservation is
+	existing, err := store.ReserveIdempotencyKey(ctx, newRecord(), expired, stale)
+	if err != nil || existing == nil || existing.StatusCode != 201 {
+		t.Fatalf("replay = %+v, %v, want the retry's 201", existing, err)
+	}
+}
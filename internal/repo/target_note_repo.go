@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"devTodTestTask/internal/models"
+	"fmt"
+	"time"
+)
+
+// AddTargetNote appends a revision to the notes of a target that may still be
+// changed and makes it the target's current notes. A non-zero version must
+// match the target's current version. It returns the target's new version.
+func (repo *MissionRepository) AddTargetNote(ctx context.Context, note *models.TargetNote, version int) (int, error) {
+	tx, err := repo.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := openTarget(ctx, tx, note.TargetID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	if note.AuthorCatID != 0 {
+		var catID uint
+		err = tx.QueryRowContext(ctx, `SELECT id FROM cats WHERE id = $1 AND deleted_at IS NULL`, note.AuthorCatID).Scan(&catID)
+		if err != nil {
+			return 0, notFoundOr(err, errInvalidNoteAuthor(note.AuthorCatID), "error checking cat")
+		}
+	}
+
+	if err = insertTargetNote(ctx, tx, note); err != nil {
+		return 0, err
+	}
+
+	var newVersion int
+	query := `UPDATE targets SET notes = $1, updated_at = $2, version = version + 1 WHERE id = $3 RETURNING version`
+	if err = tx.QueryRowContext(ctx, query, note.Body, note.CreatedAt, note.TargetID).Scan(&newVersion); err != nil {
+		return 0, fmt.Errorf("could not update target notes: %w", err)
+	}
+
+	var changes auditChanges
+	changes.add(models.AuditTarget, note.TargetID, current.MissionID, "notes", current.Notes, note.Body)
+	if err = changes.record(ctx, tx); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("could not commit target note: %w", err)
+	}
+	return newVersion, nil
+}
+
+// insertTargetNote stores the next revision of a target's notes. The caller
+// holds a lock on the target, so revisions are numbered without gaps.
+func insertTargetNote(ctx context.Context, tx *sql.Tx, note *models.TargetNote) error {
+	query := `INSERT INTO target_notes (target_id, revision, author_cat_id, body, created_at)
+			  SELECT $1, COALESCE(MAX(revision), 0) + 1, NULLIF($2, 0), $3, $4 FROM target_notes WHERE target_id = $1
+			  RETURNING id, revision, created_at`
+	err := tx.QueryRowContext(ctx, query, note.TargetID, note.AuthorCatID, note.Body, time.Now()).Scan(&note.ID, &note.Revision, &note.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("could not add target note: %w", err)
+	}
+	return nil
+}
+
+// insertInitialNote records the notes a target was created with as its first revision.
+func insertInitialNote(ctx context.Context, tx *sql.Tx, target *models.Target) error {
+	if target.Notes == "" {
+		return nil
+	}
+	return insertTargetNote(ctx, tx, &models.TargetNote{TargetID: uint(target.ID), Body: target.Notes})
+}
+
+// ListTargetNotes returns every revision of a target's notes, oldest first.
+func (repo *MissionRepository) ListTargetNotes(ctx context.Context, targetID uint) ([]models.TargetNote, error) {
+	if _, err := repo.GetTargetByID(ctx, targetID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, target_id, revision, COALESCE(author_cat_id, 0), body, created_at
+			  FROM target_notes
+			  WHERE target_id = $1
+			  ORDER BY revision`
+	rows, err := repo.DB.QueryContext(ctx, query, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get target notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []models.TargetNote{}
+	for rows.Next() {
+		var note models.TargetNote
+		if err = rows.Scan(&note.ID, &note.TargetID, &note.Revision, &note.AuthorCatID, &note.Body, &note.CreatedAt); err != nil {
+			return nil, fmt.Errorf("could not scan target note: %w", err)
+		}
+		notes = append(notes, note)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not get target notes: %w", err)
+	}
+	return notes, nil
+}
+
+// GetTargetNote returns one revision of a target's notes.
+func (repo *MissionRepository) GetTargetNote(ctx context.Context, targetID uint, revision int) (*models.TargetNote, error) {
+	if _, err := repo.GetTargetByID(ctx, targetID); err != nil {
+		return nil, err
+	}
+
+	var note models.TargetNote
+	query := `SELECT id, target_id, revision, COALESCE(author_cat_id, 0), body, created_at
+			  FROM target_notes
+			  WHERE target_id = $1 AND revision = $2`
+	err := repo.DB.QueryRowContext(ctx, query, targetID, revision).
+		Scan(&note.ID, &note.TargetID, &note.Revision, &note.AuthorCatID, &note.Body, &note.CreatedAt)
+	if err != nil {
+		return nil, notFoundOr(err, ErrNoteNotFound, "could not get target note")
+	}
+	return &note, nil
+}
@@ -3,7 +3,11 @@ package routes
 import (
 	"database/sql"
 	_ "devTodTestTask/docs"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/breeds"
+	"devTodTestTask/internal/config"
 	"devTodTestTask/internal/handlers"
+	"devTodTestTask/internal/middleware"
 	"devTodTestTask/internal/repo"
 	"devTodTestTask/internal/services"
 	"github.com/gin-gonic/gin"
@@ -11,35 +15,87 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRoutes(r *gin.Engine, db *sql.DB) {
+func SetupRoutes(r *gin.Engine, db *sql.DB, cfg config.Config, tokens *auth.TokenVerifier) {
+
+	breedCatalog := &breeds.Catalog{DB: db}
+	breedValidator := breeds.NewCache(&breeds.Fallback{
+		Primary:   breeds.NewTheCatAPI(cfg.BreedAPIURL, cfg.BreedAPITimeout, cfg.BreedAPIRetries),
+		Secondary: breedCatalog,
+	}, cfg.BreedCacheTTL)
+	breedHandler := &handlers.BreedHandler{Service: &services.BreedService{Catalog: breedCatalog}}
 
 	catRepo := &repo.CatRepository{DB: db}
-	catService := &services.CatService{Repo: catRepo}
+	auditRepo := &repo.AuditRepository{DB: db}
+	catService := &services.CatService{Repo: catRepo, Breeds: breedValidator, History: auditRepo}
 	catHandler := &handlers.CatHandler{Service: catService}
 	missionRepo := &repo.MissionRepository{DB: db}
-	missionService := &services.MissionService{Repo: missionRepo}
+	missionService := &services.MissionService{Repo: missionRepo, History: auditRepo}
 	missionHandler := &handlers.MissionHandler{Service: missionService}
+	authService := &services.AuthService{Keys: &repo.APIKeyRepository{DB: db}, Tokens: tokens}
+	// A key held longer than a request may run belongs to a request that died
+	idempotent := middleware.Idempotency(&repo.IdempotencyRepository{DB: db}, cfg.IdempotencyWindow, cfg.RequestTimeout)
+	// Every PUT, PATCH and DELETE must send If-Match, so no client overwrites a change it has not seen
+	requireIfMatch := middleware.RequireIfMatch()
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	r.Use(middleware.Errors(), middleware.Timeout(cfg.RequestTimeout), middleware.Authenticate(authService))
+
+	// Legacy routes, kept as deprecated aliases of the v2 routes below
+	r.POST("/cats", middleware.Deprecated("/v2/cats"), idempotent, catHandler.CreateCatHandler)
+	r.GET("/cats", middleware.Deprecated("/v2/cats"), catHandler.ListCatsHandler)
+	r.GET("/cats/:id", middleware.Deprecated("/v2/cats/:id"), catHandler.CatByIDHandler)
+	r.PUT("/cats", middleware.Deprecated(""), requireIfMatch, catHandler.UpdateCatHandler)
+	r.PATCH("/cats/:id", middleware.Deprecated("/v2/cats/:id"), requireIfMatch, catHandler.PatchCatHandler)
+	r.DELETE("/cats", middleware.Deprecated(""), requireIfMatch, catHandler.DeleteCatHandler)
+
+	r.GET("/breeds", middleware.Deprecated("/v2/breeds"), breedHandler.ListBreedsHandler)
+
+	r.POST("/missions", middleware.Deprecated("/v2/missions"), idempotent, missionHandler.CreateMissionHandler)
+	r.GET("/missions", middleware.Deprecated("/v2/missions"), missionHandler.ListMissionsHandler)
+	r.GET("/missions/:id", middleware.Deprecated("/v2/missions/:id"), missionHandler.GetMissionByIDHandler)
+	r.PUT("/missions/", middleware.Deprecated(""), requireIfMatch, missionHandler.UpdateMissionStatusHandler)
+	r.DELETE("/missions/:id", middleware.Deprecated("/v2/missions/:id"), requireIfMatch, missionHandler.DeleteMissionHandler)
+	r.POST("/missions/:id/transitions", middleware.Deprecated("/v2/missions/:id/transitions"), missionHandler.TransitionMissionHandler)
+
+	r.POST("/missions/:id/targets", middleware.Deprecated("/v2/missions/:id/targets"), idempotent, missionHandler.AddTargetToMissionHandler)
+	r.PUT("/missions/:id/cats/:cat_id", middleware.Deprecated("/v2/missions/:id/cats/:cat_id"), requireIfMatch, missionHandler.AssignCatToMissionHandler)
+	r.PUT("/targets/status", middleware.Deprecated(""), requireIfMatch, missionHandler.UpdateTargetStatusHandler)
+	r.PUT("/targets/notes", middleware.Deprecated(""), requireIfMatch, missionHandler.UpdateTargetNotesHandler)
+	r.DELETE("/targets/:target_id", middleware.Deprecated(""), requireIfMatch, missionHandler.DeleteTargetHandler)
+
+	v2 := r.Group("/v2", requireIfMatch)
+
+	//
+	v2.POST("/cats", idempotent, catHandler.CreateCatHandler)
+	v2.GET("/cats", catHandler.ListCatsHandler)
+	v2.GET("/cats/:id", catHandler.CatByIDHandler)
+	v2.PATCH("/cats/:id", catHandler.PatchCatHandler)
+	v2.DELETE("/cats/:id", catHandler.DeleteCatByIDHandler)
+	v2.POST("/cats/:id/restore", catHandler.RestoreCatHandler)
+	v2.GET("/cats/:id/history", catHandler.CatHistoryHandler)
+
 	//
-	r.POST("/cats", catHandler.CreateCatHandler)
-	r.GET("/cats", catHandler.ListCatsHandler)
-	r.GET("/cats/:id", catHandler.CatByIDHandler)
-	r.PUT("/cats", catHandler.UpdateCatHandler)
-	r.DELETE("/cats", catHandler.DeleteCatHandler)
+	v2.GET("/breeds", breedHandler.ListBreedsHandler)
 
 	//
-	r.POST("/missions", missionHandler.CreateMissionHandler)
-	r.GET("/missions", missionHandler.ListMissionsHandler)
-	r.GET("/missions/:id", missionHandler.GetMissionByIDHandler)
-	r.PUT("/missions/", missionHandler.UpdateMissionStatusHandler)
-	r.DELETE("/missions/:id", missionHandler.DeleteMissionHandler)
+	v2.POST("/missions", idempotent, missionHandler.CreateMissionHandler)
+	v2.GET("/missions", missionHandler.ListMissionsHandler)
+	v2.GET("/missions/:id", missionHandler.GetMissionByIDHandler)
+	v2.DELETE("/missions/:id", missionHandler.DeleteMissionHandler)
+	v2.POST("/missions/:id/transitions", missionHandler.TransitionMissionHandler)
+	v2.POST("/missions/:id/restore", missionHandler.RestoreMissionHandler)
+	v2.GET("/missions/:id/history", missionHandler.MissionHistoryHandler)
+	v2.PUT("/missions/:id/cats/:cat_id", missionHandler.AssignCatToMissionHandler)
+	v2.DELETE("/missions/:id/cats", missionHandler.UnassignCatHandler)
 
 	//
-	r.POST("/missions/:mission_id/targets", missionHandler.AddTargetToMissionHandler)
-	r.PUT("/missions/:mission_id/cats/:cat_id", missionHandler.AssignCatToMissionHandler)
-	r.PUT("/targets/status", missionHandler.UpdateTargetStatusHandler)
-	r.PUT("/targets/notes", missionHandler.UpdateTargetNotesHandler)
-	r.DELETE("/targets/:target_id", missionHandler.DeleteTargetHandler)
+	v2.POST("/missions/:id/targets", idempotent, missionHandler.AddTargetToMissionHandler)
+	v2.PUT("/missions/:id/targets/:target_id/status", missionHandler.UpdateMissionTargetStatusHandler)
+	v2.PUT("/missions/:id/targets/:target_id/notes", missionHandler.UpdateMissionTargetNotesHandler)
+	v2.DELETE("/missions/:id/targets/:target_id", missionHandler.DeleteMissionTargetHandler)
+	v2.POST("/targets/:id/restore", missionHandler.RestoreTargetHandler)
+	v2.GET("/targets/:id/notes", missionHandler.ListTargetNotesHandler)
+	v2.POST("/targets/:id/notes", missionHandler.AddTargetNoteHandler)
+	v2.GET("/targets/:id/notes/:revision", missionHandler.GetTargetNoteHandler)
 }
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/models"
+	"devTodTestTask/internal/repo"
+	"strings"
+)
+
+// AuthService resolves API keys and bearer tokens to principals and manages API keys.
+type AuthService struct {
+	Keys   repo.APIKeyStore
+	Tokens *auth.TokenVerifier
+}
+
+var errInvalidAPIKey = apperr.Unauthorized("invalid_api_key", "API key is invalid or revoked")
+
+func (s *AuthService) APIKeyPrincipal(ctx context.Context, key string) (auth.Principal, error) {
+	stored, err := s.Keys.UseAPIKey(ctx, auth.HashAPIKey(key))
+	if apperr.IsNotFound(err) {
+		return auth.Principal{}, errInvalidAPIKey
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	role, ok := auth.ParseRole(stored.Role)
+	if !ok {
+		return auth.Principal{}, errInvalidAPIKey
+	}
+	return auth.Principal{Subject: stored.Name, Role: role}, nil
+}
+
+func (s *AuthService) TokenPrincipal(ctx context.Context, token string) (auth.Principal, error) {
+	return s.Tokens.Verify(token)
+}
+
+// IssueAPIKey creates a key for the named client and returns it together with
+// its stored record. The key cannot be recovered afterwards.
+func (s *AuthService) IssueAPIKey(ctx context.Context, name string, role auth.Role) (string, *models.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, apperr.Validation("invalid_name", "name is required").
+			WithFields(apperr.FieldError{Field: "name", Message: "is required"})
+	}
+	if _, ok := auth.ParseRole(string(role)); !ok {
+		return "", nil, apperr.Validation("invalid_role", "unknown role %q", role).
+			WithFields(apperr.FieldError{Field: "role", Message: "must be admin or operator"})
+	}
+
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+	stored := &models.APIKey{Name: name, Role: string(role), KeyHash: auth.HashAPIKey(key)}
+	if err = s.Keys.CreateAPIKey(ctx, stored); err != nil {
+		return "", nil, err
+	}
+	return key, stored, nil
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.Keys.ListAPIKeys(ctx)
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, id uint) error {
+	return s.Keys.RevokeAPIKey(ctx, id)
+}
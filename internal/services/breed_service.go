@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+	"devTodTestTask/internal/breeds"
+)
+
+type BreedService struct {
+	Catalog *breeds.Catalog
+}
+
+func (s *BreedService) ListBreeds(ctx context.Context) ([]breeds.Breed, error) {
+	return s.Catalog.List(ctx)
+}
@@ -1,30 +1,132 @@
 package services
 
 import (
+	"context"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/breeds"
 	"devTodTestTask/internal/models"
 	"devTodTestTask/internal/repo"
 )
 
 type CatService struct {
-	Repo *repo.CatRepository
+	Repo    repo.CatStore
+	Breeds  breeds.Validator
+	History repo.AuditStore
 }
 
-func (s *CatService) CreateCat(cat *models.Cat) error {
-	return s.Repo.CreateCat(cat)
+// resolveBreed validates the cat's breed and replaces it with the canonical name and ID.
+func (s *CatService) resolveBreed(ctx context.Context, cat *models.Cat) error {
+	breed, err := s.Breeds.Validate(ctx, cat.Breed)
+	if err != nil {
+		return err
+	}
+	cat.Breed = breed.Name
+	cat.BreedID = breed.ID
+	return nil
 }
 
-func (s *CatService) ListCats() ([]models.Cat, error) {
-	return s.Repo.ListCats()
+func (s *CatService) CreateCat(ctx context.Context, cat *models.Cat) error {
+	if err := s.resolveBreed(ctx, cat); err != nil {
+		return err
+	}
+	return s.Repo.CreateCat(ctx, cat)
 }
 
-func (s *CatService) CatByID(id uint) (*models.Cat, error) {
-	return s.Repo.GetCatByID(id)
+func (s *CatService) ListCats(ctx context.Context, filter models.CatFilter) ([]models.Cat, int, error) {
+	if filter.IncludeDeleted {
+		if err := auth.Require(ctx, auth.PermDeletedRead); err != nil {
+			return nil, 0, err
+		}
+	}
+	return s.Repo.ListCats(ctx, filter)
 }
 
-func (s *CatService) UpdateCat(cat *models.Cat) error {
-	return s.Repo.UpdateCat(cat)
+func (s *CatService) CatByID(ctx context.Context, id uint) (*models.Cat, error) {
+	return s.Repo.GetCatByID(ctx, id)
 }
 
-func (s *CatService) DeleteCat(cat *models.Cat) error {
-	return s.Repo.DeleteCat(cat)
+// catFieldPermissions is the permission needed to change each editable cat field.
+var catFieldPermissions = map[string]auth.Permission{
+	"salary":     auth.PermCatSalary,
+	"name":       auth.PermCatProfile,
+	"experience": auth.PermCatProfile,
+	"breed":      auth.PermCatProfile,
+}
+
+// PatchCat applies a merge patch to a cat, provided the caller may edit every patched field.
+// A non-zero version must match the cat's current version.
+func (s *CatService) PatchCat(ctx context.Context, id uint, version int, patch models.CatPatch) (*models.Cat, error) {
+	principal := auth.FromContext(ctx)
+	var denied []apperr.FieldError
+	for _, field := range patch.Fields() {
+		if !principal.Can(catFieldPermissions[field]) {
+			denied = append(denied, apperr.FieldError{Field: field, Message: "role " + string(principal.Role) + " may not edit this field"})
+		}
+	}
+	if len(denied) > 0 {
+		return nil, apperr.Forbidden("field_not_editable", "not allowed to edit some fields").WithFields(denied...)
+	}
+
+	cat, err := s.Repo.GetCatByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CheckVersion(version, cat.Version); err != nil {
+		return nil, err
+	}
+
+	if patch.Name != nil {
+		cat.Name = *patch.Name
+	}
+	if patch.Experience != nil {
+		cat.Experience = *patch.Experience
+	}
+	if patch.Salary != nil {
+		cat.Salary = *patch.Salary
+	}
+	if patch.Breed != nil {
+		cat.Breed = *patch.Breed
+		if err := s.resolveBreed(ctx, cat); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Repo.UpdateCat(ctx, cat); err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+// DeleteCat retires a cat. A cat on an active mission is only deleted when the
+// retirement reassigns or unassigns that mission.
+func (s *CatService) DeleteCat(ctx context.Context, cat *models.Cat, retirement models.CatRetirement) error {
+	if retirement.ReassignTo != 0 && retirement.Unassign {
+		return apperr.Validation("conflicting_retirement", "reassign_to and unassign cannot be combined").
+			WithFields(apperr.FieldError{Field: "unassign", Message: "cannot be combined with reassign_to"})
+	}
+	if retirement.ReassignTo != 0 && retirement.ReassignTo == uint(cat.ID) {
+		return apperr.Validation("invalid_reassign_to", "cannot reassign a mission to the cat being deleted").
+			WithFields(apperr.FieldError{Field: "reassign_to", Message: "must be another cat"})
+	}
+	return s.Repo.DeleteCat(ctx, cat, retirement)
+}
+
+// RestoreCat brings back a soft-deleted cat.
+func (s *CatService) RestoreCat(ctx context.Context, id uint) (*models.Cat, error) {
+	if err := auth.Require(ctx, auth.PermDeletedRestore); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.RestoreCat(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetCatByID(ctx, id)
+}
+
+// CatHistory returns the audit trail of a cat, including the missions it was assigned to or taken off.
+func (s *CatService) CatHistory(ctx context.Context, id uint) ([]models.AuditEvent, error) {
+	if _, err := s.Repo.GetCatByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.History.CatHistory(ctx, id)
 }
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"testing"
+)
+
+func TestCreateCatCanonicalBreed(t *testing.T) {
+	cats, _ := newServices()
+	cat := &models.Cat{Name: "Tom", Breed: " SIAMESE "}
+	if err := cats.CreateCat(context.Background(), cat); err != nil {
+		t.Fatalf("CreateCat: %v", err)
+	}
+	if cat.Breed != "Siamese" || cat.BreedID != "siam" {
+		t.Errorf("breed = %q (%q), want Siamese (siam)", cat.Breed, cat.BreedID)
+	}
+
+	err := cats.CreateCat(context.Background(), &models.Cat{Name: "Rex", Breed: "dragon"})
+	wantCode(t, err, "invalid_breed")
+}
+
+func TestDeletedCatIsHidden(t *testing.T) {
+	ctx := context.Background()
+	cats, _ := newServices()
+	kept := createCat(t, cats, "Tom")
+	deleted := createCat(t, cats, "Felix")
+
+	if err := cats.DeleteCat(ctx, deleted, models.CatRetirement{}); err != nil {
+		t.Fatalf("DeleteCat: %v", err)
+	}
+
+	_, err := cats.CatByID(ctx, uint(deleted.ID))
+	wantCode(t, err, "cat_not_found")
+
+	salary := 2000.0
+	_, err = cats.PatchCat(ctx, uint(deleted.ID), 0, models.CatPatch{Salary: &salary})
+	wantCode(t, err, "cat_not_found")
+
+	err = cats.DeleteCat(ctx, &models.Cat{ID: deleted.ID}, models.CatRetirement{})
+	wantCode(t, err, "cat_not_found")
+
+	list, total, err := cats.ListCats(ctx, models.CatFilter{})
+	if err != nil {
+		t.Fatalf("ListCats: %v", err)
+	}
+	if total != 1 || len(list) != 1 || list[0].ID != kept.ID {
+		t.Errorf("ListCats = %d cats (total %d), want only cat %d", len(list), total, kept.ID)
+	}
+}
+
+func TestPatchCatVersion(t *testing.T) {
+	ctx := context.Background()
+	cats, _ := newServices()
+	cat := createCat(t, cats, "Tom")
+	id := uint(cat.ID)
+
+	salary := 1500.0
+	patched, err := cats.PatchCat(ctx, id, cat.Version, models.CatPatch{Salary: &salary})
+	if err != nil {
+		t.Fatalf("PatchCat: %v", err)
+	}
+	if patched.Version != cat.Version+1 {
+		t.Errorf("version = %d, want %d", patched.Version, cat.Version+1)
+	}
+
+	// A second writer still holding the old version must not clobber the salary
+	other := 900.0
+	_, err = cats.PatchCat(ctx, id, cat.Version, models.CatPatch{Salary: &other})
+	wantCode(t, err, "version_mismatch")
+
+	// Version 0 skips the check
+	if _, err = cats.PatchCat(ctx, id, 0, models.CatPatch{Salary: &other}); err != nil {
+		t.Fatalf("PatchCat without version: %v", err)
+	}
+
+	stale := *patched
+	err = cats.DeleteCat(ctx, &stale, models.CatRetirement{})
+	wantCode(t, err, "version_mismatch")
+}
+
+func TestDeleteCatOnActiveMission(t *testing.T) {
+	ctx := context.Background()
+	cats, missions := newServices()
+	cat := createCat(t, cats, "Tom")
+	other := createCat(t, cats, "Felix")
+	mission := createMission(t, missions, uint(cat.ID), 1)
+
+	err := cats.DeleteCat(ctx, &models.Cat{ID: cat.ID}, models.CatRetirement{})
+	wantCode(t, err, "cat_on_active_mission")
+
+	err = cats.DeleteCat(ctx, &models.Cat{ID: cat.ID}, models.CatRetirement{ReassignTo: uint(other.ID)})
+	if err != nil {
+		t.Fatalf("DeleteCat with reassign_to: %v", err)
+	}
+	got, err := missions.GetMissionByID(ctx, mission.ID)
+	if err != nil {
+		t.Fatalf("GetMissionByID: %v", err)
+	}
+	if got.CatID != uint(other.ID) || got.PreviousCatID != uint(cat.ID) {
+		t.Errorf("mission cat = %d (previous %d), want %d (previous %d)", got.CatID, got.PreviousCatID, other.ID, cat.ID)
+	}
+}
+
+func TestListDeletedCatsNeedsAdmin(t *testing.T) {
+	cats, _ := newServices()
+	createCat(t, cats, "Tom")
+	deleted := createCat(t, cats, "Felix")
+	if err := cats.DeleteCat(context.Background(), deleted, models.CatRetirement{}); err != nil {
+		t.Fatalf("DeleteCat: %v", err)
+	}
+
+	filter := models.CatFilter{IncludeDeleted: true}
+	_, _, err := cats.ListCats(context.Background(), filter)
+	wantCode(t, err, "permission_denied")
+
+	list, total, err := cats.ListCats(adminContext(), filter)
+	if err != nil {
+		t.Fatalf("ListCats as admin: %v", err)
+	}
+	if total != 2 || len(list) != 2 || list[1].DeletedAt == nil {
+		t.Errorf("ListCats = %+v (total %d), want both cats with the deleted one marked", list, total)
+	}
+}
+
+func TestRestoreCat(t *testing.T) {
+	cats, _ := newServices()
+	cat := createCat(t, cats, "Tom")
+	id := uint(cat.ID)
+
+	_, err := cats.RestoreCat(adminContext(), id)
+	wantCode(t, err, "cat_not_deleted")
+
+	if err = cats.DeleteCat(context.Background(), cat, models.CatRetirement{}); err != nil {
+		t.Fatalf("DeleteCat: %v", err)
+	}
+	_, err = cats.RestoreCat(context.Background(), id)
+	wantCode(t, err, "permission_denied")
+
+	restored, err := cats.RestoreCat(adminContext(), id)
+	if err != nil {
+		t.Fatalf("RestoreCat: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("restored cat deleted_at = %v, want nil", restored.DeletedAt)
+	}
+	if _, err = cats.CatByID(context.Background(), id); err != nil {
+		t.Errorf("CatByID after restore: %v", err)
+	}
+}
+
+func TestPatchCatProfileNeedsAdmin(t *testing.T) {
+	cats, _ := newServices()
+	cat := createCat(t, cats, "Tom")
+	id := uint(cat.ID)
+	name, breed := "Felix", "bengal"
+	patch := models.CatPatch{Name: &name, Breed: &breed}
+
+	_, err := cats.PatchCat(context.Background(), id, 0, patch)
+	wantCode(t, err, "field_not_editable")
+
+	patched, err := cats.PatchCat(adminContext(), id, 0, patch)
+	if err != nil {
+		t.Fatalf("PatchCat as admin: %v", err)
+	}
+	if patched.Name != "Felix" || patched.Breed != "Bengal" || patched.BreedID != "beng" {
+		t.Errorf("patched cat = %s, %s (%s), want Felix, Bengal (beng)", patched.Name, patched.Breed, patched.BreedID)
+	}
+
+	unknown := "dragon"
+	_, err = cats.PatchCat(adminContext(), id, 0, models.CatPatch{Breed: &unknown})
+	wantCode(t, err, "invalid_breed")
+}
@@ -1,55 +1,207 @@
 package services
 
 import (
+	"context"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/auth"
 	"devTodTestTask/internal/models"
 	"devTodTestTask/internal/repo"
-	"errors"
+	"fmt"
 )
 
 type MissionService struct {
-	Repo *repo.MissionRepository
+	Repo    repo.MissionStore
+	History repo.AuditStore
 }
 
-func (s *MissionService) CreateMission(mission *models.Mission) error {
+func (s *MissionService) CreateMission(ctx context.Context, mission *models.Mission) error {
 	if mission.IsComplete {
-		return errors.New("mission cannot be created as completed")
+		return apperr.Validation("mission_complete", "mission cannot be created as completed")
 	}
 
-	return s.Repo.CreateMission(mission)
+	if n := len(mission.Targets); n < models.MinTargetsPerMission || n > models.MaxTargetsPerMission {
+		return errInvalidTargetCount("got %d", n)
+	}
+
+	// A mission without a cat starts as a draft in the unassigned pool
+	mission.Status = models.MissionAssigned
+	if mission.CatID == 0 {
+		mission.Status = models.MissionDraft
+	}
+	return s.Repo.CreateMission(ctx, mission)
+}
+
+func (s *MissionService) ListMissions(ctx context.Context, filter models.MissionFilter) ([]models.Mission, int, error) {
+	if filter.IncludeDeleted {
+		if err := auth.Require(ctx, auth.PermDeletedRead); err != nil {
+			return nil, 0, err
+		}
+	}
+	return s.Repo.ListMissions(ctx, filter)
 }
 
-func (s *MissionService) ListMissions() ([]models.Mission, error) {
-	return s.Repo.ListMissions()
+func (s *MissionService) GetMissionByID(ctx context.Context, id uint) (*models.Mission, error) {
+	return s.Repo.GetMissionByID(ctx, id)
 }
 
-func (s *MissionService) GetMissionByID(id uint) (*models.Mission, error) {
-	return s.Repo.GetMissionByID(id)
+// UpdateMissionStatus completes a mission through the legacy is_complete flag.
+// Marking an open mission incomplete is a no-op; reopening a closed one is not allowed.
+// The mission's version is set to the version after the change.
+func (s *MissionService) UpdateMissionStatus(ctx context.Context, mission *models.Mission) error {
+	if mission.IsComplete {
+		completed, err := s.TransitionMission(ctx, mission.ID, mission.Version, models.MissionCompleted)
+		if err != nil {
+			return err
+		}
+		mission.Version = completed.Version
+		return nil
+	}
+
+	current, err := s.Repo.GetMissionByID(ctx, mission.ID)
+	if err != nil {
+		return err
+	}
+	if err := repo.CheckVersion(mission.Version, current.Version); err != nil {
+		return err
+	}
+	if current.Status.Closed() {
+		return checkTransition(current.Status, models.MissionInProgress)
+	}
+	mission.Version = current.Version
+	return nil
 }
 
-func (s *MissionService) UpdateMissionStatus(mission *models.Mission) error {
-	return s.Repo.UpdateMissionStatus(mission)
+// TransitionMission moves a mission to the given status if the lifecycle allows it.
+// A non-zero version must match the mission's current version.
+func (s *MissionService) TransitionMission(ctx context.Context, id uint, version int, to models.MissionStatus) (*models.Mission, error) {
+	mission, err := s.Repo.GetMissionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.CheckVersion(version, mission.Version); err != nil {
+		return nil, err
+	}
+
+	if err := checkTransition(mission.Status, to); err != nil {
+		return nil, err
+	}
+
+	// Only assigning a cat makes a mission assigned; see AssignCatToMission
+	if mission.CatID == 0 && (to == models.MissionAssigned || to == models.MissionInProgress) {
+		return nil, repo.ErrMissionUnassigned
+	}
+
+	// The store refuses to complete a mission with open targets under its own lock
+	if err := s.Repo.SetMissionStatus(ctx, id, mission.Version, to); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetMissionByID(ctx, id)
 }
 
-func (s *MissionService) DeleteMission(id uint) error {
-	return s.Repo.DeleteMission(id)
+func (s *MissionService) DeleteMission(ctx context.Context, id uint, version int) error {
+	return s.Repo.DeleteMission(ctx, id, version)
 }
 
-func (s *MissionService) AddTargetToMission(missionID uint, target *models.Target) error {
-	return s.Repo.AddTargetToMission(missionID, target)
+// AddTargetToMission adds a target unless the mission is closed or already
+// has the maximum number of targets; the store checks both under a lock.
+func (s *MissionService) AddTargetToMission(ctx context.Context, missionID uint, target *models.Target) error {
+	return s.Repo.AddTargetToMission(ctx, missionID, target)
 }
 
-func (s *MissionService) AssignCatToMission(missionID, catID uint) error {
-	return s.Repo.AssignCatToMission(missionID, catID)
+// AssignCatToMission assigns a cat to a mission and returns the mission's new version.
+func (s *MissionService) AssignCatToMission(ctx context.Context, missionID, catID uint, version int) (int, error) {
+	return s.Repo.AssignCatToMission(ctx, missionID, catID, version)
 }
 
-func (s *MissionService) UpdateTargetStatus(target *models.Target) error {
-	return s.Repo.UpdateTargetStatus(target)
+// TargetInMission returns ErrTargetNotFound unless the target belongs to the mission.
+func (s *MissionService) TargetInMission(ctx context.Context, missionID, targetID uint) error {
+	mission, err := s.Repo.GetMissionByID(ctx, missionID)
+	if err != nil {
+		return err
+	}
+	for _, target := range mission.Targets {
+		if uint(target.ID) == targetID {
+			return nil
+		}
+	}
+	return repo.ErrTargetNotFound
 }
 
-func (s *MissionService) UpdateTargetNotes(target *models.Target) error {
-	return s.Repo.UpdateTargetNotes(target)
+// UnassignCat takes the cat off an active mission and returns it to the unassigned pool.
+func (s *MissionService) UnassignCat(ctx context.Context, missionID uint, version int) (*models.Mission, error) {
+	if err := s.Repo.UnassignCatFromMission(ctx, missionID, version); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetMissionByID(ctx, missionID)
 }
 
-func (s *MissionService) DeleteTarget(id uint) error {
-	return s.Repo.DeleteTarget(id)
+// UpdateTargetStatus completes a target. The store starts the mission with its
+// first completed target and completes it with the last one, in the same transaction.
+func (s *MissionService) UpdateTargetStatus(ctx context.Context, target *models.Target) error {
+	return s.Repo.UpdateTargetStatus(ctx, target)
+}
+
+// UpdateTargetNotes replaces the current notes of a target with a new revision
+// whose author is unknown and sets its new version. AddTargetNote records the author.
+func (s *MissionService) UpdateTargetNotes(ctx context.Context, target *models.Target) error {
+	note := models.TargetNote{TargetID: uint(target.ID), Body: target.Notes}
+	version, err := s.Repo.AddTargetNote(ctx, &note, target.Version)
+	if err != nil {
+		return err
+	}
+	target.Version = version
+	return nil
+}
+
+// AddTargetNote appends a revision to the notes of a target. Notes are frozen
+// once the target or its mission is complete. It returns the target's new version.
+func (s *MissionService) AddTargetNote(ctx context.Context, note *models.TargetNote, version int) (int, error) {
+	return s.Repo.AddTargetNote(ctx, note, version)
+}
+
+func (s *MissionService) ListTargetNotes(ctx context.Context, targetID uint) ([]models.TargetNote, error) {
+	return s.Repo.ListTargetNotes(ctx, targetID)
+}
+
+func (s *MissionService) GetTargetNote(ctx context.Context, targetID uint, revision int) (*models.TargetNote, error) {
+	return s.Repo.GetTargetNote(ctx, targetID, revision)
+}
+
+func (s *MissionService) DeleteTarget(ctx context.Context, id uint, version int) error {
+	return s.Repo.DeleteTarget(ctx, id, version)
+}
+
+// RestoreMission brings back a soft-deleted mission with its targets.
+func (s *MissionService) RestoreMission(ctx context.Context, id uint) (*models.Mission, error) {
+	if err := auth.Require(ctx, auth.PermDeletedRestore); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.RestoreMission(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetMissionByID(ctx, id)
+}
+
+// RestoreTarget brings back a soft-deleted target of an open mission.
+func (s *MissionService) RestoreTarget(ctx context.Context, id uint) (*models.Target, error) {
+	if err := auth.Require(ctx, auth.PermDeletedRestore); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.RestoreTarget(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetTargetByID(ctx, id)
+}
+
+func errInvalidTargetCount(format string, args ...interface{}) error {
+	return apperr.Validation("invalid_target_count", "a mission must have between %d and %d targets, %s",
+		models.MinTargetsPerMission, models.MaxTargetsPerMission, fmt.Sprintf(format, args...))
+}
+
+// MissionHistory returns the audit trail of a mission and its targets.
+func (s *MissionService) MissionHistory(ctx context.Context, id uint) ([]models.AuditEvent, error) {
+	if _, err := s.Repo.GetMissionByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.History.MissionHistory(ctx, id)
 }
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"devTodTestTask/internal/models"
+	"devTodTestTask/internal/repo"
+	"testing"
+)
+
+func TestCreateMissionTargetCount(t *testing.T) {
+	_, missions := newServices()
+	for _, n := range []int{0, 4} {
+		mission := &models.Mission{}
+		for i := 0; i < n; i++ {
+			mission.Targets = append(mission.Targets, models.Target{Name: "target", Country: "UA"})
+		}
+		err := missions.CreateMission(context.Background(), mission)
+		wantCode(t, err, "invalid_target_count")
+	}
+}
+
+func TestAddTargetCap(t *testing.T) {
+	ctx := context.Background()
+	_, missions := newServices()
+	mission := createMission(t, missions, 0, 3)
+
+	err := missions.AddTargetToMission(ctx, mission.ID, &models.Target{Name: "fourth", Country: "UA"})
+	wantCode(t, err, "invalid_target_count")
+
+	// Deleted targets do not count against the cap
+	if err = missions.DeleteTarget(ctx, uint(mission.Targets[0].ID), 0); err != nil {
+		t.Fatalf("DeleteTarget: %v", err)
+	}
+	if err = missions.AddTargetToMission(ctx, mission.ID, &models.Target{Name: "fourth", Country: "UA"}); err != nil {
+		t.Fatalf("AddTargetToMission after delete: %v", err)
+	}
+}
+
+func TestOneActiveMissionPerCat(t *testing.T) {
+	ctx := context.Background()
+	cats, missions := newServices()
+	cat := createCat(t, cats, "Tom")
+	first := createMission(t, missions, uint(cat.ID), 1)
+
+	err := missions.CreateMission(ctx, &models.Mission{CatID: uint(cat.ID), Targets: []models.Target{{Name: "t", Country: "UA"}}})
+	wantCode(t, err, "cat_has_active_mission")
+
+	draft := createMission(t, missions, 0, 1)
+	_, err = missions.AssignCatToMission(ctx, draft.ID, uint(cat.ID), 0)
+	wantCode(t, err, "cat_has_active_mission")
+
+	// Once the first mission is closed the cat is free again
+	if _, err = missions.TransitionMission(ctx, first.ID, 0, models.MissionAborted); err != nil {
+		t.Fatalf("TransitionMission: %v", err)
+	}
+	version, err := missions.AssignCatToMission(ctx, draft.ID, uint(cat.ID), 0)
+	if err != nil {
+		t.Fatalf("AssignCatToMission after abort: %v", err)
+	}
+	if version != draft.Version+1 {
+		t.Errorf("AssignCatToMission returned version %d, want %d", version, draft.Version+1)
+	}
+}
+
+func TestDeletedMissionIsHidden(t *testing.T) {
+	ctx := context.Background()
+	_, missions := newServices()
+	kept := createMission(t, missions, 0, 2)
+	deleted := createMission(t, missions, 0, 1)
+
+	if err := missions.DeleteMission(ctx, deleted.ID, 0); err != nil {
+		t.Fatalf("DeleteMission: %v", err)
+	}
+	_, err := missions.GetMissionByID(ctx, deleted.ID)
+	wantCode(t, err, "mission_not_found")
+
+	target := uint(kept.Targets[0].ID)
+	if err = missions.DeleteTarget(ctx, target, 0); err != nil {
+		t.Fatalf("DeleteTarget: %v", err)
+	}
+	err = missions.UpdateTargetStatus(ctx, &models.Target{ID: int(target), IsComplete: true})
+	wantCode(t, err, "target_not_found")
+
+	list, total, err := missions.ListMissions(ctx, models.MissionFilter{})
+	if err != nil {
+		t.Fatalf("ListMissions: %v", err)
+	}
+	if total != 1 || len(list) != 1 || list[0].ID != kept.ID {
+		t.Fatalf("ListMissions = %d missions (total %d), want only mission %d", len(list), total, kept.ID)
+	}
+	if len(list[0].Targets) != 1 {
+		t.Errorf("mission has %d targets, want the deleted one hidden", len(list[0].Targets))
+	}
+}
+
+func TestMissionVersion(t *testing.T) {
+	ctx := context.Background()
+	cats, missions := newServices()
+	cat := createCat(t, cats, "Tom")
+	mission := createMission(t, missions, uint(cat.ID), 2)
+
+	moved, err := missions.TransitionMission(ctx, mission.ID, mission.Version, models.MissionInProgress)
+	if err != nil {
+		t.Fatalf("TransitionMission: %v", err)
+	}
+	if moved.Version != mission.Version+1 {
+		t.Errorf("version = %d, want %d", moved.Version, mission.Version+1)
+	}
+
+	_, err = missions.TransitionMission(ctx, mission.ID, mission.Version, models.MissionAborted)
+	wantCode(t, err, "version_mismatch")
+	err = missions.DeleteMission(ctx, mission.ID, mission.Version)
+	wantCode(t, err, "version_mismatch")
+	_, err = missions.UnassignCat(ctx, mission.ID, mission.Version)
+	wantCode(t, err, "version_mismatch")
+
+	target := mission.Targets[0]
+	update := &models.Target{ID: target.ID, Notes: "first", Version: target.Version}
+	if err = missions.UpdateTargetNotes(ctx, update); err != nil {
+		t.Fatalf("UpdateTargetNotes: %v", err)
+	}
+	if update.Version != target.Version+1 {
+		t.Errorf("UpdateTargetNotes set version %d, want %d", update.Version, target.Version+1)
+	}
+	err = missions.UpdateTargetNotes(ctx, &models.Target{ID: target.ID, Notes: "second", Version: target.Version})
+	wantCode(t, err, "version_mismatch")
+	_, err = missions.AddTargetNote(ctx, &models.TargetNote{TargetID: uint(target.ID), AuthorCatID: uint(cat.ID), Body: "third"}, target.Version)
+	wantCode(t, err, "version_mismatch")
+
+	version, err := missions.AddTargetNote(ctx, &models.TargetNote{TargetID: uint(target.ID), AuthorCatID: uint(cat.ID), Body: "third"}, update.Version)
+	if err != nil {
+		t.Fatalf("AddTargetNote: %v", err)
+	}
+	if version != update.Version+1 {
+		t.Errorf("AddTargetNote returned version %d, want %d", version, update.Version+1)
+	}
+}
+
+func TestTargetCompletionAdvancesMission(t *testing.T) {
+	ctx := context.Background()
+	cats, missions := newServices()
+	cat := createCat(t, cats, "Tom")
+	mission := createMission(t, missions, uint(cat.ID), 2)
+
+	wantStatus := func(status models.MissionStatus) {
+		t.Helper()
+		got, err := missions.GetMissionByID(ctx, mission.ID)
+		if err != nil {
+			t.Fatalf("GetMissionByID: %v", err)
+		}
+		if got.Status != status || got.IsComplete != (status == models.MissionCompleted) {
+			t.Fatalf("mission status = %s (is_complete %t), want %s", got.Status, got.IsComplete, status)
+		}
+		if (got.CompletedAt != nil) != got.IsComplete {
+			t.Errorf("completed_at = %v with is_complete %t", got.CompletedAt, got.IsComplete)
+		}
+	}
+
+	_, err := missions.TransitionMission(ctx, mission.ID, 0, models.MissionCompleted)
+	wantCode(t, err, "open_targets")
+
+	complete := func(target models.Target) {
+		t.Helper()
+		if err := missions.UpdateTargetStatus(ctx, &models.Target{ID: target.ID, IsComplete: true}); err != nil {
+			t.Fatalf("UpdateTargetStatus: %v", err)
+		}
+	}
+	complete(mission.Targets[0])
+	wantStatus(models.MissionInProgress)
+	complete(mission.Targets[1])
+	wantStatus(models.MissionCompleted)
+
+	// Notes are frozen once the target or mission is complete
+	err = missions.UpdateTargetNotes(ctx, &models.Target{ID: mission.Targets[1].ID, Notes: "late"})
+	wantCode(t, err, "target_complete")
+}
+
+func TestTargetCompletionKeepsAbortedMission(t *testing.T) {
+	ctx := context.Background()
+	cats, missions := newServices()
+	cat := createCat(t, cats, "Tom")
+	mission := createMission(t, missions, uint(cat.ID), 1)
+
+	if _, err := missions.TransitionMission(ctx, mission.ID, 0, models.MissionAborted); err != nil {
+		t.Fatalf("TransitionMission: %v", err)
+	}
+	err := missions.UpdateTargetStatus(ctx, &models.Target{ID: mission.Targets[0].ID, IsComplete: true})
+	wantCode(t, err, "mission_closed")
+}
+
+// racingStore adds a target to a mission right before its status changes, as
+// a concurrent request can between the service's checks and the update.
+type racingStore struct {
+	*repo.MemoryStore
+}
+
+func (s racingStore) SetMissionStatus(ctx context.Context, id uint, version int, status models.MissionStatus) error {
+	if err := s.AddTargetToMission(ctx, id, &models.Target{Name: "late", Country: "UA"}); err != nil {
+		return err
+	}
+	return s.MemoryStore.SetMissionStatus(ctx, id, version, status)
+}
+
+func TestCompletionRacesNewTarget(t *testing.T) {
+	ctx := context.Background()
+	store := repo.NewMemoryStore()
+	cats := &CatService{Repo: store, Breeds: testBreeds, History: store}
+	missions := &MissionService{Repo: racingStore{store}, History: store}
+	cat := createCat(t, cats, "Tom")
+	mission := createMission(t, missions, uint(cat.ID), 2)
+
+	// Leave the mission in progress with no open target
+	if err := missions.UpdateTargetStatus(ctx, &models.Target{ID: mission.Targets[0].ID, IsComplete: true}); err != nil {
+		t.Fatalf("UpdateTargetStatus: %v", err)
+	}
+	if err := missions.DeleteTarget(ctx, uint(mission.Targets[1].ID), 0); err != nil {
+		t.Fatalf("DeleteTarget: %v", err)
+	}
+	current, err := missions.GetMissionByID(ctx, mission.ID)
+	if err != nil {
+		t.Fatalf("GetMissionByID: %v", err)
+	}
+
+	_, err = missions.TransitionMission(ctx, mission.ID, current.Version, models.MissionCompleted)
+	wantCode(t, err, "open_targets")
+
+	got, err := store.GetMissionByID(ctx, mission.ID)
+	if err != nil {
+		t.Fatalf("GetMissionByID: %v", err)
+	}
+	if got.Status != models.MissionInProgress {
+		t.Errorf("mission status = %s, want in_progress", got.Status)
+	}
+}
+
+func TestTransitionWithoutCat(t *testing.T) {
+	ctx := context.Background()
+	_, missions := newServices()
+	draft := createMission(t, missions, 0, 1)
+	if draft.Status != models.MissionDraft {
+		t.Fatalf("status = %s, want draft", draft.Status)
+	}
+
+	for _, status := range []models.MissionStatus{models.MissionAssigned, models.MissionInProgress, models.MissionCompleted} {
+		_, err := missions.TransitionMission(ctx, draft.ID, 0, status)
+		if err == nil {
+			t.Errorf("transition of an unassigned draft to %s succeeded", status)
+		}
+	}
+
+	_, err := missions.TransitionMission(ctx, draft.ID, 0, models.MissionAssigned)
+	wantCode(t, err, "mission_unassigned")
+}
+
+func TestListDeletedMissionsNeedsAdmin(t *testing.T) {
+	_, missions := newServices()
+	createMission(t, missions, 0, 1)
+	deleted := createMission(t, missions, 0, 1)
+	if err := missions.DeleteMission(context.Background(), deleted.ID, 0); err != nil {
+		t.Fatalf("DeleteMission: %v", err)
+	}
+
+	filter := models.MissionFilter{IncludeDeleted: true}
+	_, _, err := missions.ListMissions(context.Background(), filter)
+	wantCode(t, err, "permission_denied")
+
+	list, total, err := missions.ListMissions(adminContext(), filter)
+	if err != nil {
+		t.Fatalf("ListMissions as admin: %v", err)
+	}
+	if total != 2 || len(list) != 2 || list[1].DeletedAt == nil {
+		t.Errorf("ListMissions = %d missions (total %d), want both with the deleted one marked", len(list), total)
+	}
+}
+
+func TestRestoreMission(t *testing.T) {
+	_, missions := newServices()
+	mission := createMission(t, missions, 0, 2)
+	if err := missions.DeleteMission(context.Background(), mission.ID, 0); err != nil {
+		t.Fatalf("DeleteMission: %v", err)
+	}
+
+	_, err := missions.RestoreMission(context.Background(), mission.ID)
+	wantCode(t, err, "permission_denied")
+
+	restored, err := missions.RestoreMission(adminContext(), mission.ID)
+	if err != nil {
+		t.Fatalf("RestoreMission: %v", err)
+	}
+	if restored.DeletedAt != nil || len(restored.Targets) != 2 {
+		t.Errorf("restored mission = %+v, want it live with both targets", restored)
+	}
+
+	_, err = missions.RestoreMission(adminContext(), mission.ID)
+	wantCode(t, err, "mission_not_deleted")
+}
+
+func TestRestoreTarget(t *testing.T) {
+	_, missions := newServices()
+	mission := createMission(t, missions, 0, 3)
+	id := uint(mission.Targets[0].ID)
+	if err := missions.DeleteTarget(context.Background(), id, 0); err != nil {
+		t.Fatalf("DeleteTarget: %v", err)
+	}
+
+	_, err := missions.RestoreTarget(context.Background(), id)
+	wantCode(t, err, "permission_denied")
+
+	// Restoring cannot take the mission past the target cap
+	extra := &models.Target{Name: "extra", Country: "UA"}
+	if err = missions.AddTargetToMission(context.Background(), mission.ID, extra); err != nil {
+		t.Fatalf("AddTargetToMission: %v", err)
+	}
+	_, err = missions.RestoreTarget(adminContext(), id)
+	wantCode(t, err, "invalid_target_count")
+
+	if err = missions.DeleteTarget(context.Background(), uint(extra.ID), 0); err != nil {
+		t.Fatalf("DeleteTarget(extra): %v", err)
+	}
+	restored, err := missions.RestoreTarget(adminContext(), id)
+	if err != nil {
+		t.Fatalf("RestoreTarget: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("restored target deleted_at = %v, want nil", restored.DeletedAt)
+	}
+}
+
+func TestDraftTargetsWaitForCat(t *testing.T) {
+	ctx := context.Background()
+	cats, missions := newServices()
+	cat := createCat(t, cats, "Tom")
+	draft := createMission(t, missions, 0, 1)
+	target := &models.Target{ID: draft.Targets[0].ID, IsComplete: true}
+
+	err := missions.UpdateTargetStatus(ctx, target)
+	wantCode(t, err, "mission_unassigned")
+
+	if _, err = missions.AssignCatToMission(ctx, draft.ID, uint(cat.ID), 0); err != nil {
+		t.Fatalf("AssignCatToMission: %v", err)
+	}
+	if err = missions.UpdateTargetStatus(ctx, target); err != nil {
+		t.Fatalf("UpdateTargetStatus after assignment: %v", err)
+	}
+	got, err := missions.GetMissionByID(ctx, draft.ID)
+	if err != nil {
+		t.Fatalf("GetMissionByID: %v", err)
+	}
+	if got.Status != models.MissionCompleted {
+		t.Errorf("mission status = %s, want completed", got.Status)
+	}
+}
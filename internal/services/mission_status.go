@@ -0,0 +1,23 @@
+package services
+
+import (
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/models"
+)
+
+// checkTransition returns a Validation error for unknown statuses and a
+// Conflict error, listing the allowed statuses, for illegal transitions.
+func checkTransition(from, to models.MissionStatus) error {
+	if !to.Valid() {
+		return apperr.Validation("unknown_status", "unknown mission status %q", to)
+	}
+	if from.CanTransitionTo(to) {
+		return nil
+	}
+	return apperr.Conflict("illegal_transition", "cannot transition mission from %s to %s", from, to).
+		WithDetails(map[string]interface{}{
+			"from":    from,
+			"to":      to,
+			"allowed": from.Next(),
+		})
+}
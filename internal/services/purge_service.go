@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"devTodTestTask/internal/repo"
+	"fmt"
+	"time"
+)
+
+// PurgeService hard-deletes rows that have been soft-deleted for longer than
+// Retention, and idempotency keys older than KeyWindow when Keys is set.
+type PurgeService struct {
+	Repo      repo.PurgeStore
+	Retention time.Duration
+
+	Keys      repo.IdempotencyStore
+	KeyWindow time.Duration
+}
+
+func (s *PurgeService) Purge(ctx context.Context) (repo.PurgeResult, error) {
+	result, err := s.Repo.PurgeDeleted(ctx, time.Now().Add(-s.Retention))
+	if err != nil || s.Keys == nil {
+		return result, err
+	}
+	result.IdempotencyKeys, err = s.Keys.PurgeIdempotencyKeys(ctx, time.Now().Add(-s.KeyWindow))
+	return result, err
+}
+
+// Run purges once per interval until ctx is cancelled.
+func (s *PurgeService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.Purge(ctx)
+		if err != nil {
+			fmt.Printf("Purging deleted rows failed. Error: %v\n", err)
+		} else if result != (repo.PurgeResult{}) {
+			fmt.Printf("Purged %d cats, %d missions and %d targets deleted more than %s ago and %d expired idempotency keys\n",
+				result.Cats, result.Missions, result.Targets, s.Retention, result.IdempotencyKeys)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
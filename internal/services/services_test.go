@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"devTodTestTask/internal/apperr"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/breeds"
+	"devTodTestTask/internal/models"
+	"devTodTestTask/internal/repo"
+	"strings"
+	"testing"
+)
+
+// stubBreeds accepts the breeds it lists, matched case-insensitively, and
+// returns their canonical names like the real validators do.
+type stubBreeds map[string]breeds.Breed
+
+func (b stubBreeds) Validate(ctx context.Context, name string) (*breeds.Breed, error) {
+	breed, ok := b[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, breeds.ErrUnknownBreed
+	}
+	return &breed, nil
+}
+
+var testBreeds = stubBreeds{
+	"siamese": {ID: "siam", Name: "Siamese"},
+	"bengal":  {ID: "beng", Name: "Bengal"},
+}
+
+// newServices returns cat and mission services sharing one in-memory store.
+func newServices() (*CatService, *MissionService) {
+	store := repo.NewMemoryStore()
+	return &CatService{Repo: store, Breeds: testBreeds, History: store},
+		&MissionService{Repo: store, History: store}
+}
+
+func createCat(t *testing.T, s *CatService, name string) *models.Cat {
+	t.Helper()
+	cat := &models.Cat{Name: name, Experience: 3, Breed: "siamese", Salary: 1000}
+	if err := s.CreateCat(context.Background(), cat); err != nil {
+		t.Fatalf("CreateCat(%s): %v", name, err)
+	}
+	return cat
+}
+
+// createMission creates a mission with n targets, assigned to catID unless it is 0.
+func createMission(t *testing.T, s *MissionService, catID uint, n int) *models.Mission {
+	t.Helper()
+	mission := &models.Mission{CatID: catID}
+	for i := 0; i < n; i++ {
+		mission.Targets = append(mission.Targets, models.Target{Name: "target", Country: "UA", Notes: "initial"})
+	}
+	if err := s.CreateMission(context.Background(), mission); err != nil {
+		t.Fatalf("CreateMission: %v", err)
+	}
+	return mission
+}
+
+// adminContext returns a context carrying an admin principal, as the
+// Authenticate middleware would for an admin API key.
+func adminContext() context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{Subject: "admin", Role: auth.RoleAdmin})
+}
+
+// wantCode fails the test unless err is an *apperr.Error with the given code.
+func wantCode(t *testing.T, err error, code string) {
+	t.Helper()
+	appErr, ok := apperr.As(err)
+	if !ok {
+		t.Fatalf("got error %v, want %s", err, code)
+	}
+	if appErr.Code != code {
+		t.Fatalf("got error code %s (%v), want %s", appErr.Code, err, code)
+	}
+}
@@ -1,19 +1,57 @@
 package main
 
 import (
+	"context"
+	"devTodTestTask/internal/auth"
+	"devTodTestTask/internal/breeds"
 	"devTodTestTask/internal/config"
+	"devTodTestTask/internal/repo"
 	"devTodTestTask/internal/routes"
+	"devTodTestTask/internal/services"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
 
+// @title Spy Cats API
+// @version 2.0
+// @description Manage spy cats, their missions and targets.
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued with "admin issue".
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description "Bearer <API key>" or "Bearer <JWT>", with the JWT signed by HS256 or RS256.
 func main() {
 
+	cfg := config.Load()
 	db := config.ConnectDB()
+
+	catalog := &breeds.Catalog{DB: db}
+	if err := catalog.Seed(context.Background()); err != nil {
+		fmt.Printf("Seeding breed catalog failed. Error: %v\n", err)
+	}
+
+	purge := &services.PurgeService{
+		Repo:      &repo.PurgeRepository{DB: db},
+		Retention: cfg.PurgeRetention,
+		Keys:      &repo.IdempotencyRepository{DB: db},
+		KeyWindow: cfg.IdempotencyWindow,
+	}
+	go purge.Run(context.Background(), cfg.PurgeInterval)
+
+	tokens, err := auth.NewTokenVerifier(cfg.Tokens)
+	if err != nil {
+		fmt.Printf("Loading JWT keys failed. Error: %v\n", err)
+		return
+	}
+
 	r := gin.Default()
-	routes.SetupRoutes(r, db)
+	routes.SetupRoutes(r, db, cfg, tokens)
 
-	err := r.Run(":8080")
+	err = r.Run(":8080")
 	if err != nil {
 		return
 	}
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS missions_one_active_per_cat_idx;
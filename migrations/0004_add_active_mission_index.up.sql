@@ -0,0 +1,18 @@
+-- Before this index, concurrent creates could give a cat more than one active
+-- mission. Keep the newest active mission of each cat and soft-delete the
+-- others so that the index can be built. They are not marked complete because
+-- their targets were never done; an admin can still review and restore them.
+UPDATE missions
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE is_complete = FALSE
+  AND deleted_at IS NULL
+  AND EXISTS (SELECT 1
+              FROM missions newer
+              WHERE newer.cat_id = missions.cat_id
+                AND newer.is_complete = FALSE
+                AND newer.deleted_at IS NULL
+                AND newer.id > missions.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS missions_one_active_per_cat_idx
+    ON missions (cat_id)
+    WHERE is_complete = FALSE AND deleted_at IS NULL;
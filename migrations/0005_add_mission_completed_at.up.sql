@@ -0,0 +1,3 @@
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+UPDATE missions SET completed_at = COALESCE(updated_at, created_at) WHERE is_complete = TRUE;
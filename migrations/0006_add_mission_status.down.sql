@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS missions_one_active_per_cat_idx;
+
+UPDATE missions SET is_complete = TRUE WHERE status = 'aborted';
+
+ALTER TABLE missions DROP COLUMN IF EXISTS status;
+
+CREATE UNIQUE INDEX IF NOT EXISTS missions_one_active_per_cat_idx
+    ON missions (cat_id)
+    WHERE is_complete = FALSE AND deleted_at IS NULL;
@@ -0,0 +1,16 @@
+ALTER TABLE missions
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'assigned'
+        CHECK (status IN ('draft', 'assigned', 'in_progress', 'completed', 'aborted'));
+
+UPDATE missions SET status = 'completed' WHERE is_complete = TRUE;
+
+UPDATE missions
+SET status = 'in_progress'
+WHERE is_complete = FALSE
+  AND EXISTS (SELECT 1 FROM targets WHERE targets.mission_id = missions.id AND targets.is_complete = TRUE);
+
+DROP INDEX IF EXISTS missions_one_active_per_cat_idx;
+
+CREATE UNIQUE INDEX missions_one_active_per_cat_idx
+    ON missions (cat_id)
+    WHERE status NOT IN ('completed', 'aborted') AND deleted_at IS NULL;
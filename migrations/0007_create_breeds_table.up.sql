@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS breeds (
+                                      id VARCHAR(16) PRIMARY KEY,
+                                      name VARCHAR(100) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS breeds_lower_name_idx ON breeds (LOWER(name));
@@ -0,0 +1,6 @@
+ALTER TABLE cats ADD COLUMN IF NOT EXISTS breed_id VARCHAR(16);
+
+UPDATE cats
+SET breed = breeds.name, breed_id = breeds.id
+FROM breeds
+WHERE LOWER(TRIM(cats.breed)) = LOWER(breeds.name);
@@ -0,0 +1,4 @@
+-- Unassigned missions cannot be kept once every mission needs a cat again
+DELETE FROM missions WHERE cat_id IS NULL;
+
+ALTER TABLE missions ALTER COLUMN cat_id SET NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE missions ALTER COLUMN cat_id DROP NOT NULL;
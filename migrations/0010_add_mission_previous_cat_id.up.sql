@@ -0,0 +1,2 @@
+ALTER TABLE missions
+    ADD COLUMN IF NOT EXISTS previous_cat_id INTEGER REFERENCES cats(id) ON DELETE SET NULL;
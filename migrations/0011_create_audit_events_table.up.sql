@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+                                            id BIGSERIAL PRIMARY KEY,
+                                            actor VARCHAR(255) NOT NULL,
+                                            entity_type VARCHAR(16) NOT NULL CHECK (entity_type IN ('cat', 'mission', 'target')),
+                                            entity_id INTEGER NOT NULL,
+                                            mission_id INTEGER,
+                                            field VARCHAR(32) NOT NULL,
+                                            old_value TEXT,
+                                            new_value TEXT,
+                                            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_entity_idx ON audit_events (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_events_mission_idx ON audit_events (mission_id);
+
+-- Audit events are append-only: rows can be inserted but never changed or removed.
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
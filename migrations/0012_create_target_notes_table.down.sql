@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS target_notes;
+DROP FUNCTION IF EXISTS target_notes_append_only();
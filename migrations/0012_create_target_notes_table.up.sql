@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS target_notes (
+                                            id SERIAL PRIMARY KEY,
+                                            target_id INTEGER NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+                                            revision INTEGER NOT NULL,
+                                            author_cat_id INTEGER REFERENCES cats(id),
+                                            body TEXT NOT NULL,
+                                            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                                            UNIQUE (target_id, revision)
+);
+
+-- The notes written so far become the first revision, without a known author.
+INSERT INTO target_notes (target_id, revision, body, created_at)
+SELECT id, 1, notes, COALESCE(updated_at, created_at, NOW())
+FROM targets
+WHERE COALESCE(notes, '') <> '';
+
+-- Note revisions are append-only; they only go away together with their target.
+CREATE OR REPLACE FUNCTION target_notes_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'target_notes is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER target_notes_append_only
+    BEFORE UPDATE ON target_notes
+    FOR EACH ROW EXECUTE FUNCTION target_notes_append_only();
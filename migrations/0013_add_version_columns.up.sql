@@ -0,0 +1,3 @@
+ALTER TABLE cats ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
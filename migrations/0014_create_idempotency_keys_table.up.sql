@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+                                                subject VARCHAR(255) NOT NULL,
+                                                idempotency_key VARCHAR(255) NOT NULL,
+                                                request_hash CHAR(64) NOT NULL,
+                                                status_code INTEGER,
+                                                headers JSONB,
+                                                body BYTEA,
+                                                created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                                                PRIMARY KEY (subject, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_at_idx ON idempotency_keys (created_at);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+                                        id SERIAL PRIMARY KEY,
+                                        name VARCHAR(255) NOT NULL,
+                                        role VARCHAR(16) NOT NULL CHECK (role IN ('admin', 'operator')),
+                                        key_hash CHAR(64) NOT NULL UNIQUE,
+                                        created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                                        last_used_at TIMESTAMP,
+                                        revoked_at TIMESTAMP
+);
+
+-- A name identifies its key in the audit trail, so it is unique among keys in use.
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_active_name_idx ON api_keys (name) WHERE revoked_at IS NULL;
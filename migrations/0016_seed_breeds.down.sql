@@ -0,0 +1 @@
+-- Seeded breeds and normalized cat breeds are kept; 0008 and 0007 drop them.
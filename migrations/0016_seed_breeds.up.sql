@@ -0,0 +1,79 @@
+-- The breeds table used to be filled only when the app started, after the
+-- migrations had run, so the backfill in 0008 found no breeds to match. Seed
+-- the bundled catalog (internal/breeds/catalog.json) here and normalize the
+-- cats 0008 left behind.
+INSERT INTO breeds (id, name) VALUES
+    ('abys', 'Abyssinian'),
+    ('aege', 'Aegean'),
+    ('abob', 'American Bobtail'),
+    ('acur', 'American Curl'),
+    ('asho', 'American Shorthair'),
+    ('awir', 'American Wirehair'),
+    ('amau', 'Arabian Mau'),
+    ('amis', 'Australian Mist'),
+    ('bali', 'Balinese'),
+    ('bamb', 'Bambino'),
+    ('beng', 'Bengal'),
+    ('birm', 'Birman'),
+    ('bomb', 'Bombay'),
+    ('bslo', 'British Longhair'),
+    ('bsho', 'British Shorthair'),
+    ('bure', 'Burmese'),
+    ('buri', 'Burmilla'),
+    ('cspa', 'California Spangled'),
+    ('ctif', 'Chantilly-Tiffany'),
+    ('char', 'Chartreux'),
+    ('chau', 'Chausie'),
+    ('chee', 'Cheetoh'),
+    ('csho', 'Colorpoint Shorthair'),
+    ('crex', 'Cornish Rex'),
+    ('cymr', 'Cymric'),
+    ('cypr', 'Cyprus'),
+    ('drex', 'Devon Rex'),
+    ('dons', 'Donskoy'),
+    ('lihu', 'Dragon Li'),
+    ('emau', 'Egyptian Mau'),
+    ('ebur', 'European Burmese'),
+    ('esho', 'Exotic Shorthair'),
+    ('hbro', 'Havana Brown'),
+    ('hima', 'Himalayan'),
+    ('jbob', 'Japanese Bobtail'),
+    ('java', 'Javanese'),
+    ('khao', 'Khao Manee'),
+    ('kora', 'Korat'),
+    ('kuri', 'Kurilian'),
+    ('lape', 'LaPerm'),
+    ('mcoo', 'Maine Coon'),
+    ('mala', 'Malayan'),
+    ('manx', 'Manx'),
+    ('munc', 'Munchkin'),
+    ('nebe', 'Nebelung'),
+    ('norw', 'Norwegian Forest Cat'),
+    ('ocic', 'Ocicat'),
+    ('orie', 'Oriental'),
+    ('pers', 'Persian'),
+    ('pixi', 'Pixie-bob'),
+    ('raga', 'Ragamuffin'),
+    ('ragd', 'Ragdoll'),
+    ('rblu', 'Russian Blue'),
+    ('sava', 'Savannah'),
+    ('sfol', 'Scottish Fold'),
+    ('srex', 'Selkirk Rex'),
+    ('siam', 'Siamese'),
+    ('sibe', 'Siberian'),
+    ('sing', 'Singapura'),
+    ('snow', 'Snowshoe'),
+    ('soma', 'Somali'),
+    ('sphy', 'Sphynx'),
+    ('tonk', 'Tonkinese'),
+    ('toyg', 'Toyger'),
+    ('tang', 'Turkish Angora'),
+    ('tvan', 'Turkish Van'),
+    ('ycho', 'York Chocolate')
+ON CONFLICT DO NOTHING;
+
+UPDATE cats
+SET breed = breeds.name, breed_id = breeds.id
+FROM breeds
+WHERE cats.breed_id IS NULL
+  AND LOWER(TRIM(cats.breed)) = LOWER(breeds.name);